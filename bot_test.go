@@ -0,0 +1,329 @@
+package tgbotapi
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestBot starts a server answering every request with handler and
+// returns a Bot pointed at it.
+func newTestBot(t *testing.T, handler http.HandlerFunc) (*Bot, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	bot := &Bot{
+		token:    "TOKEN",
+		endpoint: server.URL,
+		client:   server.Client(),
+	}
+
+	return bot, server
+}
+
+func TestNewBotWithOptions(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{"ok":true,"result":{"id":1,"first_name":"Test","username":"test_bot"}}`))
+	}))
+	defer server.Close()
+
+	bot, err := NewBotWithOptions("TOKEN", BotOptions{
+		Endpoint: server.URL + "/",
+		Client:   server.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/botTOKEN/getMe" {
+		t.Errorf("unexpected request path %q", path)
+	}
+	if bot.UserName() != "test_bot" {
+		t.Errorf("unexpected username %q", bot.UserName())
+	}
+}
+
+func TestUploadFile(t *testing.T) {
+	var uploaded []string
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botTOKEN/sendDocument" {
+			t.Errorf("unexpected request path %q", r.URL.Path)
+		}
+
+		f, header, err := r.FormFile("document")
+		if err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: no document"}`))
+			return
+		}
+		data, _ := ioutil.ReadAll(f)
+		uploaded = append(uploaded, header.Filename+":"+string(data))
+
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	})
+
+	name := filepath.Join(t.TempDir(), "file.txt")
+	if err := ioutil.WriteFile(name, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	files := []interface{}{
+		name,
+		FileBytes{Name: "bytes.txt", Bytes: []byte("bytes")},
+		FileReader{Name: "reader.txt", Reader: strings.NewReader("reader"), Size: -1},
+		FileReader{Name: "unsized.txt", Reader: strings.NewReader("unsized")},
+	}
+	for _, file := range files {
+		if _, err := bot.SendDocument(NewDocumentUpload(1, file)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{"file.txt:hello", "bytes.txt:bytes", "reader.txt:reader", "unsized.txt:unsized"}
+	if strings.Join(uploaded, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected uploads %v, got %v", expected, uploaded)
+	}
+}
+
+func TestMakeRequestContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := bot.SendMessageContext(ctx, NewMessage(1, "hello"))
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// zeroReader is an endless stream of zeros.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestUploadFileStreams(t *testing.T) {
+	const size = 64 << 20
+
+	var received int64
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		n, err := io.Copy(ioutil.Discard, r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		if r.ContentLength != n {
+			t.Errorf("Content-Length %d does not match body length %d", r.ContentLength, n)
+		}
+		received = n
+
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	})
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	file := FileReader{Name: "video.mp4", Reader: io.LimitReader(zeroReader{}, size), Size: size}
+	if _, err := bot.SendVideo(NewVideoUpload(1, file)); err != nil {
+		t.Fatal(err)
+	}
+
+	runtime.ReadMemStats(&after)
+
+	if received < size {
+		t.Errorf("expected at least %d bytes, got %d", size, received)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > size/8 {
+		t.Errorf("uploading %d bytes allocated %d bytes", size, allocated)
+	}
+}
+
+func TestUploadFileReaderRetry(t *testing.T) {
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(ioutil.Discard, r.Body)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	bot.retry = &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	// A plain io.Reader can't be sent twice, so the first error is returned.
+	file := FileReader{Name: "file.txt", Reader: io.LimitReader(zeroReader{}, 10), Size: 10}
+	if _, err := bot.UploadFile("sendDocument", nil, "document", file); !hasCode(err, http.StatusBadGateway) {
+		t.Errorf("expected a 502 error, got %v", err)
+	}
+}
+
+func TestUploadProgress(t *testing.T) {
+	actions := make(chan string, 10)
+	var action string
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/sendChatAction") {
+			r.ParseForm()
+			actions <- r.Form.Get("action")
+			w.Write([]byte(`{"ok":true,"result":true}`))
+			return
+		}
+
+		io.Copy(ioutil.Discard, r.Body)
+
+		// The action is sent alongside the upload, give it time to arrive.
+		select {
+		case action = <-actions:
+		case <-time.After(time.Second):
+		}
+
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	})
+
+	var sent, total int64
+	config := NewDocumentUpload(1, FileBytes{Name: "file.txt", Bytes: make([]byte, 100000)})
+	config.ChatAction = ChatUploadDocument
+	config.Progress = func(s, t int64) {
+		if s < sent {
+			panic("progress went backwards")
+		}
+		sent, total = s, t
+	}
+
+	if _, err := bot.SendDocument(config); err != nil {
+		t.Fatal(err)
+	}
+
+	if sent != 100000 || total != 100000 {
+		t.Errorf("expected 100000 of 100000 bytes sent, got %d of %d", sent, total)
+	}
+	if action != ChatUploadDocument {
+		t.Errorf("expected a %s action, got %q", ChatUploadDocument, action)
+	}
+}
+
+func TestSendChatAction(t *testing.T) {
+	requests := 0
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+
+	// Chat actions return true rather than a message.
+	message, err := bot.Send(NewChatAction(1, ChatTyping))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.MessageID != 0 {
+		t.Errorf("expected an empty message, got %+v", message)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestUploadChatActionNotRateLimited(t *testing.T) {
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/sendDocument") {
+			io.Copy(ioutil.Discard, r.Body)
+
+			// A slow upload, long enough for the chat's slot to free up
+			// unless the chat action takes it.
+			time.Sleep(1100 * time.Millisecond)
+		}
+
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	})
+	bot.limiter = NewRateLimiter(RateLimitConfig{})
+
+	config := NewDocumentUpload(1, FileBytes{Name: "file.txt", Bytes: []byte("hello")})
+	config.ChatAction = ChatUploadDocument
+	if _, err := bot.SendDocument(config); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := bot.SendMessage(NewMessage(1, "done")); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited > 500*time.Millisecond {
+		t.Errorf("expected the message after the upload not to wait, waited %v", waited)
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/botTOKEN/getFile":
+			r.ParseForm()
+			size := map[string]string{"small": "5", "large": "5000", "lying": "5"}[r.Form.Get("file_id")]
+			w.Write([]byte(`{"ok":true,"result":{"file_id":"` + r.Form.Get("file_id") + `","file_size":` + size + `,"file_path":"documents/` + r.Form.Get("file_id") + `.txt"}}`))
+		case "/file/botTOKEN/documents/small.txt":
+			w.Write([]byte("hello"))
+		case "/file/botTOKEN/documents/lying.txt":
+			w.(http.Flusher).Flush()
+			w.Write(make([]byte, 2000))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	bot.maxDownload = 1000
+
+	var b strings.Builder
+	if _, err := bot.DownloadFile("small", &b); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "hello" {
+		t.Errorf("expected hello, got %q", b.String())
+	}
+
+	if _, err := bot.DownloadFile("large", ioutil.Discard); err != ErrFileTooLarge {
+		t.Errorf("expected ErrFileTooLarge, got %v", err)
+	}
+	b.Reset()
+	if n, err := bot.DownloadFile("lying", &b); err != ErrFileTooLarge {
+		t.Errorf("expected ErrFileTooLarge, got %v", err)
+	} else if n > 1000 || b.Len() > 1000 {
+		t.Errorf("expected at most 1000 bytes to be written, got %d (%d reported)", b.Len(), n)
+	}
+}
+
+func TestSendPhotoShare(t *testing.T) {
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.URL.Path != "/botTOKEN/sendPhoto" {
+			t.Errorf("unexpected request path %q", r.URL.Path)
+		}
+		if r.Form.Get("photo") != "FILE" || r.Form.Get("reply_to_message_id") != "5" || r.Form.Get("caption") != "hi" {
+			t.Errorf("unexpected form %v", r.Form)
+		}
+
+		w.Write([]byte(`{"ok":true,"result":{"message_id":6,"caption":"hi"}}`))
+	})
+
+	config := NewPhotoShare(1, "FILE")
+	config.ReplyToMessageID = 5
+	config.Caption = "hi"
+
+	message, err := bot.Send(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.MessageID != 6 {
+		t.Errorf("unexpected message %+v", message)
+	}
+}
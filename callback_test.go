@@ -0,0 +1,107 @@
+package tgbotapi_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	tgbotapi "github.com/pho/telegram-bot-api"
+	"github.com/pho/telegram-bot-api/tgbotapitest"
+)
+
+func TestCallbackQuery(t *testing.T) {
+	server := tgbotapitest.NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := tgbotapi.NewMessage(42, "Pick one")
+	config.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Yes", "answer:yes"),
+			tgbotapi.NewInlineKeyboardButtonData("No", "answer:no"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL("Help", "https://example.com"),
+			tgbotapi.NewInlineKeyboardButtonSwitch("Share", ""),
+		),
+	)
+	message, err := bot.Send(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"inline_keyboard":[[{"text":"Yes","callback_data":"answer:yes"},{"text":"No","callback_data":"answer:no"}],` +
+		`[{"text":"Help","url":"https://example.com"},{"text":"Share","switch_inline_query":""}]]}`
+	if markup := server.Sent()[0].Params.Get("reply_markup"); markup != expected {
+		t.Errorf("expected markup %s, got %s", expected, markup)
+	}
+
+	server.AddCallbackQuery(message, "answer:yes")
+
+	updates, err := bot.GetUpdatesChan(tgbotapi.UpdateConfig{Timeout: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bot.StopReceivingUpdates()
+
+	router := tgbotapi.NewRouter(bot)
+	router.HandleFallback(func(bot *tgbotapi.Bot, update tgbotapi.Update) {
+		t.Errorf("expected the callback query handler, got %+v", update)
+	})
+	router.HandleCallbackQuery(func(bot *tgbotapi.Bot, update tgbotapi.Update) {
+		q := update.CallbackQuery
+		if q.Data != "answer:yes" || q.Message == nil || q.Message.MessageID != message.MessageID {
+			t.Errorf("unexpected callback query %+v", q)
+		}
+
+		if err := bot.AnswerCallbackQuery(tgbotapi.NewCallback(q.ID, "Thanks!")); err != nil {
+			t.Error(err)
+		}
+	})
+	router.HandleUpdate(<-updates)
+
+	sent := server.Sent()
+	if len(sent) != 2 || sent[1].Method != "answerCallbackQuery" {
+		t.Fatalf("expected the callback query to be answered, got %+v", sent)
+	}
+	if text := sent[1].Params.Get("text"); text != "Thanks!" {
+		t.Errorf("expected the answer text, got %q", text)
+	}
+	if sent[1].Params.Get("callback_query_id") == "" {
+		t.Error("expected a callback_query_id")
+	}
+}
+
+func TestCallbackQueryUpdate(t *testing.T) {
+	var update tgbotapi.Update
+	data := `{"update_id":1,"callback_query":{"id":"4382","from":{"id":7},"inline_message_id":"AAA","data":"x"}}`
+	if err := json.Unmarshal([]byte(data), &update); err != nil {
+		t.Fatal(err)
+	}
+
+	q := update.CallbackQuery
+	if q == nil || q.ID != "4382" || q.From.ID != 7 || q.Message != nil || q.InlineMessageID != "AAA" || q.Data != "x" {
+		t.Errorf("unexpected callback query %+v", q)
+	}
+}
+
+func TestSendCallback(t *testing.T) {
+	server := tgbotapitest.NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Answers return true rather than a message, even through Send.
+	if _, err := bot.Send(tgbotapi.NewCallback("4382", "Thanks!")); err != nil {
+		t.Fatal(err)
+	}
+	if sent := server.Sent(); len(sent) != 1 || sent[0].Method != "answerCallbackQuery" {
+		t.Errorf("expected the callback query to be answered once, got %+v", sent)
+	}
+}
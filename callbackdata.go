@@ -0,0 +1,236 @@
+package tgbotapi
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// callbackSignatureSize is how many bytes of the HMAC are kept.
+// Eight bytes leave a forger one chance in 2^64 per try.
+const callbackSignatureSize = 8
+
+// storedCallbackPrefix marks callback data kept in a CallbackDataStore.
+const storedCallbackPrefix = "~"
+
+var callbackEscaper = strings.NewReplacer("%", "%25", "|", "%7C", "~", "%7E")
+var callbackUnescaper = strings.NewReplacer("%7E", "~", "%7C", "|", "%25", "%")
+
+// CallbackData is a structured payload for an inline button: an action
+// saying what the button does, and fields it needs to do it.
+type CallbackData struct {
+	Action string
+	Fields []string
+}
+
+// NewCallbackData creates CallbackData, formatting each field with fmt.Sprint.
+func NewCallbackData(action string, fields ...interface{}) CallbackData {
+	data := CallbackData{Action: action}
+	for _, field := range fields {
+		data.Fields = append(data.Fields, fmt.Sprint(field))
+	}
+
+	return data
+}
+
+// String returns field i, or "" if there is no such field.
+func (d CallbackData) String(i int) string {
+	if i < 0 || i >= len(d.Fields) {
+		return ""
+	}
+
+	return d.Fields[i]
+}
+
+// Int returns field i as an int.
+func (d CallbackData) Int(i int) (int, error) {
+	return strconv.Atoi(d.String(i))
+}
+
+// Bool returns field i as a bool.
+func (d CallbackData) Bool(i int) (bool, error) {
+	return strconv.ParseBool(d.String(i))
+}
+
+// CallbackDataStore keeps callback data too large to fit in a button,
+// keyed by a short ID. It must be safe for concurrent use.
+type CallbackDataStore interface {
+	// Get returns the data for id, and false if there is none.
+	Get(id string) (string, bool, error)
+	// Set stores data for id. It may be dropped after expires, unless
+	// expires is zero.
+	Set(id string, data string, expires time.Time) error
+}
+
+// MemoryCallbackDataStore is a CallbackDataStore keeping data in memory.
+// Data is dropped once it expires; data that never expires is kept until
+// the program exits.
+type MemoryCallbackDataStore struct {
+	mu      sync.Mutex
+	entries map[string]storedCallbackData
+	sets    int
+}
+
+type storedCallbackData struct {
+	data    string
+	expires time.Time
+}
+
+// NewMemoryCallbackDataStore creates an empty MemoryCallbackDataStore.
+func NewMemoryCallbackDataStore() *MemoryCallbackDataStore {
+	return &MemoryCallbackDataStore{
+		entries: make(map[string]storedCallbackData),
+	}
+}
+
+// Get returns the data for id, and false if there is none.
+func (s *MemoryCallbackDataStore) Get(id string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	return entry.data, ok, nil
+}
+
+// Set stores data for id until expires.
+func (s *MemoryCallbackDataStore) Set(id string, data string, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop expired data now and then, so the map doesn't keep growing.
+	s.sets++
+	if s.sets%100 == 0 {
+		now := time.Now()
+		for id, entry := range s.entries {
+			if !entry.expires.IsZero() && now.After(entry.expires) {
+				delete(s.entries, id)
+			}
+		}
+	}
+
+	s.entries[id] = storedCallbackData{data, expires}
+
+	return nil
+}
+
+// CallbackCodec packs CallbackData into the callback data of inline
+// buttons, signed so that data from modified clients is rejected.
+//
+// Callback data is limited to MaxCallbackDataSize bytes. Larger payloads
+// are kept in Store, and the button only carries a short signed ID.
+type CallbackCodec struct {
+	// TTL is how long encoded data can be decoded for. It never expires
+	// if TTL is zero.
+	TTL time.Duration
+	// Store keeps payloads too large for a button. If it is nil, encoding
+	// them fails with ErrCallbackDataTooLarge.
+	Store CallbackDataStore
+
+	secret []byte
+}
+
+// NewCallbackCodec creates a CallbackCodec signing data with secret, which
+// should be at least 32 random bytes kept private to the bot.
+func NewCallbackCodec(secret []byte) *CallbackCodec {
+	return &CallbackCodec{
+		secret: secret,
+	}
+}
+
+// Encode packs data into a string for InlineKeyboardButton.CallbackData.
+func (c *CallbackCodec) Encode(data CallbackData) (string, error) {
+	parts := []string{callbackEscaper.Replace(data.Action)}
+	for _, field := range data.Fields {
+		parts = append(parts, callbackEscaper.Replace(field))
+	}
+
+	var expires time.Time
+	payload := strings.Join(parts, "|") + "|"
+	if c.TTL > 0 {
+		expires = time.Now().Add(c.TTL)
+		payload += strconv.FormatInt(expires.Unix(), 36)
+	}
+
+	encoded := payload + "|" + c.sign(payload)
+	if len(encoded) <= MaxCallbackDataSize {
+		return encoded, nil
+	}
+
+	if c.Store == nil {
+		return "", ErrCallbackDataTooLarge
+	}
+
+	id := make([]byte, 9)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	key := storedCallbackPrefix + base64.RawURLEncoding.EncodeToString(id)
+	if err := c.Store.Set(key, payload, expires); err != nil {
+		return "", err
+	}
+
+	return key + "|" + c.sign(key), nil
+}
+
+// Decode unpacks data made by Encode. It returns ErrCallbackDataInvalid if
+// the data was not made by a codec with the same secret or was changed,
+// and ErrCallbackDataExpired if it is older than the TTL it was made with.
+func (c *CallbackCodec) Decode(s string) (CallbackData, error) {
+	i := strings.LastIndex(s, "|")
+	if i < 0 || !hmac.Equal([]byte(s[i+1:]), []byte(c.sign(s[:i]))) {
+		return CallbackData{}, ErrCallbackDataInvalid
+	}
+
+	payload := s[:i]
+	if strings.HasPrefix(payload, storedCallbackPrefix) {
+		if c.Store == nil {
+			return CallbackData{}, ErrCallbackDataInvalid
+		}
+
+		stored, ok, err := c.Store.Get(payload)
+		if err != nil {
+			return CallbackData{}, err
+		}
+		if !ok {
+			return CallbackData{}, ErrCallbackDataExpired
+		}
+		payload = stored
+	}
+
+	parts := strings.Split(payload, "|")
+	if len(parts) < 2 {
+		return CallbackData{}, ErrCallbackDataInvalid
+	}
+
+	if expires := parts[len(parts)-1]; expires != "" {
+		unix, err := strconv.ParseInt(expires, 36, 64)
+		if err != nil {
+			return CallbackData{}, ErrCallbackDataInvalid
+		}
+		if time.Now().After(time.Unix(unix, 0)) {
+			return CallbackData{}, ErrCallbackDataExpired
+		}
+	}
+
+	data := CallbackData{Action: callbackUnescaper.Replace(parts[0])}
+	for _, field := range parts[1 : len(parts)-1] {
+		data.Fields = append(data.Fields, callbackUnescaper.Replace(field))
+	}
+
+	return data, nil
+}
+
+// sign returns the truncated HMAC of s.
+func (c *CallbackCodec) sign(s string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(s))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:callbackSignatureSize])
+}
@@ -0,0 +1,94 @@
+package tgbotapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCallbackCodec(t *testing.T) {
+	codec := NewCallbackCodec([]byte("secret"))
+	codec.TTL = time.Hour
+
+	data := NewCallbackData("buy", 42, true, "a|b~%7C")
+	encoded, err := codec.Encode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(encoded) > MaxCallbackDataSize {
+		t.Errorf("expected at most %d bytes, got %q", MaxCallbackDataSize, encoded)
+	}
+
+	decoded, err := codec.Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, data) {
+		t.Errorf("expected %+v, got %+v", data, decoded)
+	}
+	if n, err := decoded.Int(0); n != 42 || err != nil {
+		t.Errorf("expected field 0 to be 42, got %d, %v", n, err)
+	}
+	if b, err := decoded.Bool(1); !b || err != nil {
+		t.Errorf("expected field 1 to be true, got %v, %v", b, err)
+	}
+	if s := decoded.String(3); s != "" {
+		t.Errorf("expected a missing field to be empty, got %q", s)
+	}
+
+	tampered := strings.Replace(encoded, "42", "43", 1)
+	if _, err := codec.Decode(tampered); err != ErrCallbackDataInvalid {
+		t.Errorf("expected tampered data to be rejected, got %v", err)
+	}
+	if _, err := NewCallbackCodec([]byte("other")).Decode(encoded); err != ErrCallbackDataInvalid {
+		t.Errorf("expected data signed with another secret to be rejected, got %v", err)
+	}
+	if _, err := codec.Decode("buy"); err != ErrCallbackDataInvalid {
+		t.Errorf("expected unsigned data to be rejected, got %v", err)
+	}
+
+	codec.TTL = time.Nanosecond
+	encoded, _ = codec.Encode(data)
+	if _, err := codec.Decode(encoded); err != ErrCallbackDataExpired {
+		t.Errorf("expected expired data to be rejected, got %v", err)
+	}
+}
+
+func TestCallbackCodecStore(t *testing.T) {
+	codec := NewCallbackCodec([]byte("secret"))
+	data := NewCallbackData("search", strings.Repeat("long query ", 10))
+
+	if _, err := codec.Encode(data); err != ErrCallbackDataTooLarge {
+		t.Errorf("expected large data to fail without a store, got %v", err)
+	}
+
+	codec.Store = NewMemoryCallbackDataStore()
+	encoded, err := codec.Encode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(encoded) > MaxCallbackDataSize || !strings.HasPrefix(encoded, storedCallbackPrefix) {
+		t.Errorf("expected a short ID, got %q", encoded)
+	}
+
+	decoded, err := codec.Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, data) {
+		t.Errorf("expected %+v, got %+v", data, decoded)
+	}
+
+	// IDs can't be guessed, as they are signed too.
+	forged := storedCallbackPrefix + "AAAAAAAAAAAA" + encoded[strings.Index(encoded, "|"):]
+	if _, err := codec.Decode(forged); err != ErrCallbackDataInvalid {
+		t.Errorf("expected a forged ID to be rejected, got %v", err)
+	}
+
+	// Data dropped from the store has expired.
+	codec.Store = NewMemoryCallbackDataStore()
+	if _, err := codec.Decode(encoded); err != ErrCallbackDataExpired {
+		t.Errorf("expected missing data to have expired, got %v", err)
+	}
+}
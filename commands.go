@@ -0,0 +1,153 @@
+package tgbotapi
+
+import (
+	"strings"
+	"unicode"
+)
+
+// IsCommand returns true if the message starts with a bot command,
+// such as "/start".
+func (m Message) IsCommand() bool {
+	return len(m.Text) > 1 && m.Text[0] == '/' && !unicode.IsSpace(rune(m.Text[1]))
+}
+
+// CommandWithAt returns the command the message starts with, without the
+// slash but with any @botname, such as "start@MyBot". It returns "" if
+// the message isn't a command.
+func (m Message) CommandWithAt() string {
+	if !m.IsCommand() {
+		return ""
+	}
+
+	command := m.Text[1:]
+	if i := strings.IndexFunc(command, unicode.IsSpace); i >= 0 {
+		command = command[:i]
+	}
+
+	return command
+}
+
+// Command returns the command the message starts with, without the slash,
+// such as "start". It returns "" if the message isn't a command.
+//
+// A message doesn't know which bot is reading it, so an @botname is left in
+// place: "/start@OtherBot" gives "start@OtherBot", which matches no handler
+// for "start". Use Bot.Command to also accept commands naming this bot.
+func (m Message) Command() string {
+	return m.CommandWithAt()
+}
+
+// CommandMention returns the bot username a command was sent to, such as
+// "MyBot" for "/start@MyBot", or "" if there is none.
+func (m Message) CommandMention() string {
+	command := m.CommandWithAt()
+	if i := strings.Index(command, "@"); i >= 0 {
+		return command[i+1:]
+	}
+
+	return ""
+}
+
+// CommandArguments returns the text after the command, or "" if the
+// message isn't a command. Use SplitArguments to split it into words.
+func (m Message) CommandArguments() string {
+	if !m.IsCommand() {
+		return ""
+	}
+
+	i := strings.IndexFunc(m.Text, unicode.IsSpace)
+	if i < 0 {
+		return ""
+	}
+
+	return strings.TrimSpace(m.Text[i:])
+}
+
+// Command returns the command a message starts with, without the slash or
+// @botname, if it is meant for this bot. It returns "" if the message isn't
+// a command, or the command names another bot, like "/start@OtherBot".
+func (bot *Bot) Command(m Message) string {
+	if !bot.IsCommandForMe(m) {
+		return ""
+	}
+
+	command := m.CommandWithAt()
+	if i := strings.Index(command, "@"); i >= 0 {
+		command = command[:i]
+	}
+
+	return command
+}
+
+// IsCommandForMe returns true if the message is a command either without
+// an @botname, or mentioning this bot. Commands with an @botname are never
+// for a Bot that wasn't created by NewBot, as its username isn't known.
+func (bot *Bot) IsCommandForMe(m Message) bool {
+	if !m.IsCommand() {
+		return false
+	}
+
+	mention := m.CommandMention()
+	if mention == "" {
+		return true
+	}
+
+	return bot.self != nil && strings.EqualFold(mention, bot.self.UserName)
+}
+
+// SplitArguments splits command arguments into words the way a shell does.
+// Words are separated by whitespace, and may be quoted with single or
+// double quotes to include whitespace. Outside of single quotes, a
+// backslash escapes the next character.
+//
+//	`add "buy milk" 'and eggs' 2\ kg` → ["add", "buy milk", "and eggs", "2 kg"]
+//
+// ErrUnterminatedQuote is returned if a quote isn't closed.
+func SplitArguments(s string) ([]string, error) {
+	var (
+		args   []string
+		word   []rune
+		inWord bool
+		quote  rune
+		escape bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escape:
+			word = append(word, r)
+			escape = false
+		case r == '\\' && quote != '\'':
+			escape = true
+			inWord = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word = append(word, r)
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				args = append(args, string(word))
+				word = word[:0]
+				inWord = false
+			}
+		default:
+			word = append(word, r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, ErrUnterminatedQuote
+	}
+	if escape {
+		word = append(word, '\\')
+	}
+	if inWord {
+		args = append(args, string(word))
+	}
+
+	return args, nil
+}
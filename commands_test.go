@@ -0,0 +1,92 @@
+package tgbotapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMessageCommand(t *testing.T) {
+	tests := []struct {
+		text, command, withAt, mention, arguments string
+	}{
+		{"/start", "start", "start", "", ""},
+		{"/start@MyBot arg1  arg2 ", "start@MyBot", "start@MyBot", "MyBot", "arg1  arg2"},
+		{"/help\nmore", "help", "help", "", "more"},
+		{"hello /start", "", "", "", ""},
+		{"/", "", "", "", ""},
+		{"/ start", "", "", "", ""},
+	}
+
+	for _, test := range tests {
+		m := Message{Text: test.text}
+		if m.IsCommand() != (test.command != "") {
+			t.Errorf("%q: expected IsCommand to be %v", test.text, test.command != "")
+		}
+		if command := m.Command(); command != test.command {
+			t.Errorf("%q: expected command %q, got %q", test.text, test.command, command)
+		}
+		if withAt := m.CommandWithAt(); withAt != test.withAt {
+			t.Errorf("%q: expected command with at %q, got %q", test.text, test.withAt, withAt)
+		}
+		if mention := m.CommandMention(); mention != test.mention {
+			t.Errorf("%q: expected mention %q, got %q", test.text, test.mention, mention)
+		}
+		if arguments := m.CommandArguments(); arguments != test.arguments {
+			t.Errorf("%q: expected arguments %q, got %q", test.text, test.arguments, arguments)
+		}
+	}
+}
+
+func TestIsCommandForMe(t *testing.T) {
+	bot := &Bot{self: &User{UserName: "MyBot"}}
+
+	tests := map[string]bool{
+		"/start":          true,
+		"/start@MyBot":    true,
+		"/start@mybot":    true,
+		"/start@OtherBot": false,
+		"start":           false,
+	}
+
+	for text, expected := range tests {
+		if bot.IsCommandForMe(Message{Text: text}) != expected {
+			t.Errorf("%q: expected %v", text, expected)
+		}
+
+		command := bot.Command(Message{Text: text})
+		if expected && command != "start" || !expected && command != "" {
+			t.Errorf("%q: unexpected command %q", text, command)
+		}
+	}
+}
+
+func TestSplitArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"", nil},
+		{"  one two\tthree ", []string{"one", "two", "three"}},
+		{`add "buy milk" 'and eggs' 2\ kg`, []string{"add", "buy milk", "and eggs", "2 kg"}},
+		{`"it's" 'say "hi"' a"b c"d`, []string{"it's", `say "hi"`, "ab cd"}},
+		{`"" ''`, []string{"", ""}},
+		{`'back\slash' "\"quoted\"" end\`, []string{`back\slash`, `"quoted"`, `end\`}},
+	}
+
+	for _, test := range tests {
+		args, err := SplitArguments(test.input)
+		if err != nil {
+			t.Errorf("%q: %v", test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(args, test.expected) {
+			t.Errorf("%q: expected %q, got %q", test.input, test.expected, args)
+		}
+	}
+
+	for _, input := range []string{`"open`, `it's`} {
+		if _, err := SplitArguments(input); err != ErrUnterminatedQuote {
+			t.Errorf("%q: expected ErrUnterminatedQuote, got %v", input, err)
+		}
+	}
+}
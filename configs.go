@@ -0,0 +1,458 @@
+package tgbotapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// Chattable is a config for a request that sends something to a chat.
+// It is sent with Bot.Send.
+type Chattable interface {
+	// method returns the name of the API method to call.
+	method() string
+	// values returns the parameters of the request.
+	values() (url.Values, error)
+}
+
+// Fileable is a Chattable that may upload a file.
+type Fileable interface {
+	Chattable
+	// upload returns the file to upload along with the values,
+	// or nil when reusing a file already on Telegram's servers.
+	upload() *fileUpload
+}
+
+// fileUpload describes the file a Fileable uploads.
+type fileUpload struct {
+	field      string
+	file       interface{}
+	progress   ProgressFunc
+	chatAction string
+}
+
+// chatValues returns the parameters most requests to a chat have in common.
+func chatValues(chatID int, replyToMessageID int, replyMarkup interface{}) (url.Values, error) {
+	v := url.Values{}
+	v.Add("chat_id", strconv.Itoa(chatID))
+	if replyToMessageID != 0 {
+		v.Add("reply_to_message_id", strconv.Itoa(replyToMessageID))
+	}
+	if replyMarkup != nil {
+		if markup, ok := replyMarkup.(interface {
+			Validate() error
+		}); ok {
+			if err := markup.Validate(); err != nil {
+				return v, err
+			}
+		}
+
+		data, err := json.Marshal(replyMarkup)
+		if err != nil {
+			return v, err
+		}
+
+		v.Add("reply_markup", string(data))
+	}
+
+	return v, nil
+}
+
+// fileValues returns the parameters for sending a file, adding the
+// existing FileID when the file isn't uploaded.
+func fileValues(chatID int, replyToMessageID int, replyMarkup interface{}, field string, useExisting bool, fileID string) (url.Values, error) {
+	v, err := chatValues(chatID, replyToMessageID, replyMarkup)
+	if err != nil {
+		return v, err
+	}
+
+	if useExisting {
+		v.Add(field, fileID)
+	}
+
+	return v, nil
+}
+
+// FileBytes contains information about a set of bytes to upload as a File.
+type FileBytes struct {
+	Name  string
+	Bytes []byte
+}
+
+// FileReader contains information about a reader to upload as a File.
+// Leave Size unset, or set it to -1, if it is not known.
+type FileReader struct {
+	Name   string
+	Reader io.Reader
+	Size   int64
+}
+
+// ProgressFunc is called while uploading a file with the number of bytes
+// sent so far and the size of the file, which is -1 if it isn't known.
+type ProgressFunc func(sent, total int64)
+
+// MessageConfig contains information about a SendMessage request.
+type MessageConfig struct {
+	ChatID                int
+	Text                  string
+	DisableWebPagePreview bool
+	ReplyToMessageID      int
+	ReplyMarkup           interface{}
+}
+
+func (config MessageConfig) method() string {
+	return "sendMessage"
+}
+
+func (config MessageConfig) values() (url.Values, error) {
+	v, err := chatValues(config.ChatID, config.ReplyToMessageID, config.ReplyMarkup)
+	v.Add("text", config.Text)
+	v.Add("disable_web_page_preview", strconv.FormatBool(config.DisableWebPagePreview))
+
+	return v, err
+}
+
+// ForwardConfig contains infomation about a ForwardMessage request.
+type ForwardConfig struct {
+	ChatID     int
+	FromChatID int
+	MessageID  int
+}
+
+func (config ForwardConfig) method() string {
+	return "forwardMessage"
+}
+
+func (config ForwardConfig) values() (url.Values, error) {
+	v, err := chatValues(config.ChatID, 0, nil)
+	v.Add("from_chat_id", strconv.Itoa(config.FromChatID))
+	v.Add("message_id", strconv.Itoa(config.MessageID))
+
+	return v, err
+}
+
+// PhotoConfig contains information about a SendPhoto request.
+type PhotoConfig struct {
+	ChatID           int
+	Caption          string
+	ReplyToMessageID int
+	ReplyMarkup      interface{}
+	UseExistingPhoto bool
+	File             interface{}
+	FilePath         string
+	FileID           string
+	Progress         ProgressFunc
+	ChatAction       string
+}
+
+func (config PhotoConfig) method() string {
+	return "sendPhoto"
+}
+
+func (config PhotoConfig) values() (url.Values, error) {
+	v, err := fileValues(config.ChatID, config.ReplyToMessageID, config.ReplyMarkup, "photo", config.UseExistingPhoto, config.FileID)
+	if config.Caption != "" {
+		v.Add("caption", config.Caption)
+	}
+
+	return v, err
+}
+
+func (config PhotoConfig) upload() *fileUpload {
+	if config.UseExistingPhoto {
+		return nil
+	}
+
+	return &fileUpload{"photo", fileOrPath(config.File, config.FilePath), config.Progress, config.ChatAction}
+}
+
+// AudioConfig contains information about a SendAudio request.
+type AudioConfig struct {
+	ChatID           int
+	ReplyToMessageID int
+	ReplyMarkup      interface{}
+	UseExistingAudio bool
+	File             interface{}
+	FilePath         string
+	FileID           string
+	Progress         ProgressFunc
+	ChatAction       string
+}
+
+func (config AudioConfig) method() string {
+	return "sendAudio"
+}
+
+func (config AudioConfig) values() (url.Values, error) {
+	return fileValues(config.ChatID, config.ReplyToMessageID, config.ReplyMarkup, "audio", config.UseExistingAudio, config.FileID)
+}
+
+func (config AudioConfig) upload() *fileUpload {
+	if config.UseExistingAudio {
+		return nil
+	}
+
+	return &fileUpload{"audio", fileOrPath(config.File, config.FilePath), config.Progress, config.ChatAction}
+}
+
+// DocumentConfig contains information about a SendDocument request.
+type DocumentConfig struct {
+	ChatID              int
+	ReplyToMessageID    int
+	ReplyMarkup         interface{}
+	UseExistingDocument bool
+	File                interface{}
+	FilePath            string
+	FileID              string
+	Progress            ProgressFunc
+	ChatAction          string
+}
+
+func (config DocumentConfig) method() string {
+	return "sendDocument"
+}
+
+func (config DocumentConfig) values() (url.Values, error) {
+	return fileValues(config.ChatID, config.ReplyToMessageID, config.ReplyMarkup, "document", config.UseExistingDocument, config.FileID)
+}
+
+func (config DocumentConfig) upload() *fileUpload {
+	if config.UseExistingDocument {
+		return nil
+	}
+
+	return &fileUpload{"document", fileOrPath(config.File, config.FilePath), config.Progress, config.ChatAction}
+}
+
+// StickerConfig contains information about a SendSticker request.
+type StickerConfig struct {
+	ChatID             int
+	ReplyToMessageID   int
+	ReplyMarkup        interface{}
+	UseExistingSticker bool
+	File               interface{}
+	FilePath           string
+	FileID             string
+	Progress           ProgressFunc
+	ChatAction         string
+}
+
+func (config StickerConfig) method() string {
+	return "sendSticker"
+}
+
+func (config StickerConfig) values() (url.Values, error) {
+	return fileValues(config.ChatID, config.ReplyToMessageID, config.ReplyMarkup, "sticker", config.UseExistingSticker, config.FileID)
+}
+
+func (config StickerConfig) upload() *fileUpload {
+	if config.UseExistingSticker {
+		return nil
+	}
+
+	return &fileUpload{"sticker", fileOrPath(config.File, config.FilePath), config.Progress, config.ChatAction}
+}
+
+// VideoConfig contains information about a SendVideo request.
+type VideoConfig struct {
+	ChatID           int
+	ReplyToMessageID int
+	ReplyMarkup      interface{}
+	UseExistingVideo bool
+	File             interface{}
+	FilePath         string
+	FileID           string
+	Progress         ProgressFunc
+	ChatAction       string
+}
+
+func (config VideoConfig) method() string {
+	return "sendVideo"
+}
+
+func (config VideoConfig) values() (url.Values, error) {
+	return fileValues(config.ChatID, config.ReplyToMessageID, config.ReplyMarkup, "video", config.UseExistingVideo, config.FileID)
+}
+
+func (config VideoConfig) upload() *fileUpload {
+	if config.UseExistingVideo {
+		return nil
+	}
+
+	return &fileUpload{"video", fileOrPath(config.File, config.FilePath), config.Progress, config.ChatAction}
+}
+
+// LocationConfig contains information about a SendLocation request.
+type LocationConfig struct {
+	ChatID           int
+	Latitude         float64
+	Longitude        float64
+	ReplyToMessageID int
+	ReplyMarkup      interface{}
+}
+
+func (config LocationConfig) method() string {
+	return "sendLocation"
+}
+
+func (config LocationConfig) values() (url.Values, error) {
+	v, err := chatValues(config.ChatID, config.ReplyToMessageID, config.ReplyMarkup)
+	v.Add("latitude", strconv.FormatFloat(config.Latitude, 'f', 6, 64))
+	v.Add("longitude", strconv.FormatFloat(config.Longitude, 'f', 6, 64))
+
+	return v, err
+}
+
+// ChatActionConfig contains information about a SendChatAction request.
+type ChatActionConfig struct {
+	ChatID int
+	Action string
+}
+
+func (config ChatActionConfig) method() string {
+	return "sendChatAction"
+}
+
+func (config ChatActionConfig) values() (url.Values, error) {
+	v, err := chatValues(config.ChatID, 0, nil)
+	v.Add("action", config.Action)
+
+	return v, err
+}
+
+// editValues returns the parameters for editing a message, which is
+// identified either by chatID and messageID or by inlineMessageID.
+func editValues(chatID int, messageID int, inlineMessageID string, replyMarkup *InlineKeyboardMarkup) (url.Values, error) {
+	v := url.Values{}
+	if inlineMessageID != "" {
+		v.Add("inline_message_id", inlineMessageID)
+	} else {
+		v.Add("chat_id", strconv.Itoa(chatID))
+		v.Add("message_id", strconv.Itoa(messageID))
+	}
+
+	if replyMarkup != nil {
+		if err := replyMarkup.Validate(); err != nil {
+			return v, err
+		}
+
+		data, err := json.Marshal(replyMarkup)
+		if err != nil {
+			return v, err
+		}
+
+		v.Add("reply_markup", string(data))
+	}
+
+	return v, nil
+}
+
+// EditMessageTextConfig contains information about an EditMessageText request.
+type EditMessageTextConfig struct {
+	ChatID                int
+	MessageID             int
+	InlineMessageID       string
+	Text                  string
+	DisableWebPagePreview bool
+	ReplyMarkup           *InlineKeyboardMarkup
+}
+
+func (config EditMessageTextConfig) method() string {
+	return "editMessageText"
+}
+
+func (config EditMessageTextConfig) values() (url.Values, error) {
+	v, err := editValues(config.ChatID, config.MessageID, config.InlineMessageID, config.ReplyMarkup)
+	v.Add("text", config.Text)
+	v.Add("disable_web_page_preview", strconv.FormatBool(config.DisableWebPagePreview))
+
+	return v, err
+}
+
+// EditMessageCaptionConfig contains information about an EditMessageCaption request.
+type EditMessageCaptionConfig struct {
+	ChatID          int
+	MessageID       int
+	InlineMessageID string
+	Caption         string
+	ReplyMarkup     *InlineKeyboardMarkup
+}
+
+func (config EditMessageCaptionConfig) method() string {
+	return "editMessageCaption"
+}
+
+func (config EditMessageCaptionConfig) values() (url.Values, error) {
+	v, err := editValues(config.ChatID, config.MessageID, config.InlineMessageID, config.ReplyMarkup)
+	v.Add("caption", config.Caption)
+
+	return v, err
+}
+
+// EditMessageReplyMarkupConfig contains information about an
+// EditMessageReplyMarkup request.
+type EditMessageReplyMarkupConfig struct {
+	ChatID          int
+	MessageID       int
+	InlineMessageID string
+	ReplyMarkup     *InlineKeyboardMarkup
+}
+
+func (config EditMessageReplyMarkupConfig) method() string {
+	return "editMessageReplyMarkup"
+}
+
+func (config EditMessageReplyMarkupConfig) values() (url.Values, error) {
+	return editValues(config.ChatID, config.MessageID, config.InlineMessageID, config.ReplyMarkup)
+}
+
+// DeleteMessageConfig contains information about a DeleteMessage request.
+type DeleteMessageConfig struct {
+	ChatID    int
+	MessageID int
+}
+
+func (config DeleteMessageConfig) method() string {
+	return "deleteMessage"
+}
+
+func (config DeleteMessageConfig) values() (url.Values, error) {
+	v, err := chatValues(config.ChatID, 0, nil)
+	v.Add("message_id", strconv.Itoa(config.MessageID))
+
+	return v, err
+}
+
+// CallbackConfig contains information about an AnswerCallbackQuery request.
+type CallbackConfig struct {
+	CallbackQueryID string
+	Text            string
+	ShowAlert       bool
+}
+
+func (config CallbackConfig) method() string {
+	return "answerCallbackQuery"
+}
+
+func (config CallbackConfig) values() (url.Values, error) {
+	v := url.Values{}
+	v.Add("callback_query_id", config.CallbackQueryID)
+	if config.Text != "" {
+		v.Add("text", config.Text)
+	}
+	if config.ShowAlert {
+		v.Add("show_alert", "true")
+	}
+
+	return v, nil
+}
+
+// fileOrPath returns the file to upload for a config's File and FilePath.
+func fileOrPath(file interface{}, path string) interface{} {
+	if file != nil {
+		return file
+	}
+
+	return path
+}
@@ -0,0 +1,351 @@
+package tgbotapi
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// EndConversation is returned by a StateFunc to end the conversation.
+const EndConversation = ""
+
+// ConversationKey identifies a conversation with a user in a chat.
+type ConversationKey struct {
+	ChatID int
+	UserID int
+}
+
+// ConversationState is what a ConversationStore keeps for a conversation.
+type ConversationState struct {
+	State   string
+	Data    map[string]string
+	Expires time.Time
+}
+
+// ConversationStore stores the state of conversations, so it can be kept
+// somewhere other than memory, such as a database shared by several
+// instances of a bot. It must be safe for concurrent use.
+type ConversationStore interface {
+	// Get returns the state for key, and false if there is none.
+	Get(key ConversationKey) (ConversationState, bool, error)
+	Set(key ConversationKey, state ConversationState) error
+	Delete(key ConversationKey) error
+}
+
+// MemoryConversationStore is a ConversationStore keeping state in memory.
+// State is dropped some time after it expires, so abandoned conversations
+// don't pile up; users coming back after that aren't sent a TimeoutText.
+type MemoryConversationStore struct {
+	mu     sync.Mutex
+	states map[ConversationKey]ConversationState
+	sets   int
+}
+
+// NewMemoryConversationStore creates an empty MemoryConversationStore.
+func NewMemoryConversationStore() *MemoryConversationStore {
+	return &MemoryConversationStore{
+		states: make(map[ConversationKey]ConversationState),
+	}
+}
+
+// Get returns the state for key, and false if there is none.
+func (s *MemoryConversationStore) Get(key ConversationKey) (ConversationState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[key]
+	state.Data = copyData(state.Data)
+
+	return state, ok, nil
+}
+
+// Set stores the state for key.
+func (s *MemoryConversationStore) Set(key ConversationKey, state ConversationState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop expired state now and then, so the map doesn't keep growing.
+	s.sets++
+	if s.sets%100 == 0 {
+		now := time.Now()
+		for key, state := range s.states {
+			if !state.Expires.IsZero() && now.After(state.Expires) {
+				delete(s.states, key)
+			}
+		}
+	}
+
+	state.Data = copyData(state.Data)
+	s.states[key] = state
+
+	return nil
+}
+
+// Delete removes the state for key.
+func (s *MemoryConversationStore) Delete(key ConversationKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, key)
+
+	return nil
+}
+
+func copyData(data map[string]string) map[string]string {
+	c := make(map[string]string, len(data))
+	for k, v := range data {
+		c[k] = v
+	}
+
+	return c
+}
+
+// Conversation is passed to a StateFunc with the conversation's state.
+// Changes to Data are stored after the StateFunc returns.
+type Conversation struct {
+	Bot   *Bot
+	Key   ConversationKey
+	State string
+	Data  map[string]string
+}
+
+// StateFunc handles a message sent in a conversation state. It returns
+// the state to move to, which may be the same one to ask again, or
+// EndConversation. If it returns an error the state is left unchanged.
+type StateFunc func(c *Conversation, message Message) (string, error)
+
+// Prompt is sent to the user when a conversation enters a state.
+type Prompt struct {
+	Text string
+	// Options are shown to the user as a one time reply keyboard. Without
+	// options, the user is asked to reply with ForceReply.
+	Options [][]string
+}
+
+type conversationState struct {
+	prompt  Prompt
+	handler StateFunc
+}
+
+// ConversationManager runs multi-step dialogs, keeping track of which
+// state each user is in with each chat.
+//
+// Start a conversation with Begin, typically from a command handler, and
+// pass every update to HandleUpdate before routing it anywhere else:
+//
+//	if !conversations.HandleUpdate(update) {
+//		router.HandleUpdate(update)
+//	}
+//
+// Updates for a conversation must be handled one at a time.
+type ConversationManager struct {
+	// Timeout ends conversations the user hasn't replied to in time.
+	// Conversations don't time out if it is zero.
+	Timeout time.Duration
+	// TimeoutText is sent when a message arrives in a conversation that
+	// has timed out. The message is then handled as if there was no
+	// conversation.
+	TimeoutText string
+	// CancelCommands end a conversation, such as "cancel" for /cancel.
+	CancelCommands []string
+	// CancelText is sent when a conversation is cancelled.
+	CancelText string
+	// ErrorHandler is called with errors from StateFuncs, the store, or
+	// sending prompts. By default they are logged.
+	ErrorHandler func(error)
+
+	bot    *Bot
+	store  ConversationStore
+	states map[string]conversationState
+}
+
+// NewConversationManager creates a ConversationManager keeping state in
+// store. A MemoryConversationStore is used if store is nil.
+func NewConversationManager(bot *Bot, store ConversationStore) *ConversationManager {
+	if store == nil {
+		store = NewMemoryConversationStore()
+	}
+
+	return &ConversationManager{
+		bot:    bot,
+		store:  store,
+		states: make(map[string]conversationState),
+	}
+}
+
+// HandleState declares a state, sending prompt when a conversation enters
+// it and passing the user's reply to handler.
+func (m *ConversationManager) HandleState(state string, prompt Prompt, handler StateFunc) {
+	m.states[state] = conversationState{prompt, handler}
+}
+
+// Begin starts a conversation in state with the sender of message in its
+// chat, replacing any conversation already going on.
+func (m *ConversationManager) Begin(message Message, state string) error {
+	if _, ok := m.states[state]; !ok {
+		return fmt.Errorf("conversation state %q not declared", state)
+	}
+
+	c := m.conversation(message)
+	c.State = state
+
+	return m.enter(c, message)
+}
+
+// End ends the conversation with the sender of message in its chat.
+func (m *ConversationManager) End(message Message) error {
+	return m.store.Delete(m.conversation(message).Key)
+}
+
+// HandleUpdate passes the update to the conversation it belongs to, if any,
+// returning true if the update was handled.
+func (m *ConversationManager) HandleUpdate(update Update) bool {
+	if update.CallbackQuery != nil {
+		return false
+	}
+
+	message := update.Message
+	c := m.conversation(message)
+
+	state, ok, err := m.store.Get(c.Key)
+	if err != nil {
+		m.handleError(err)
+		return false
+	}
+	if !ok {
+		return false
+	}
+
+	if !state.Expires.IsZero() && time.Now().After(state.Expires) {
+		m.handleError(m.store.Delete(c.Key))
+		if m.TimeoutText != "" {
+			m.handleError(m.reply(message, m.TimeoutText, ReplyKeyboardHide{HideKeyboard: true, Selective: true}))
+		}
+		return false
+	}
+
+	if m.isCancel(message) {
+		m.handleError(m.store.Delete(c.Key))
+		if m.CancelText != "" {
+			m.handleError(m.reply(message, m.CancelText, ReplyKeyboardHide{HideKeyboard: true, Selective: true}))
+		}
+		return true
+	}
+
+	s, ok := m.states[state.State]
+	if !ok {
+		m.handleError(m.store.Delete(c.Key))
+		m.handleError(fmt.Errorf("conversation state %q not declared", state.State))
+		return false
+	}
+
+	c.State = state.State
+	c.Data = state.Data
+
+	next, err := s.handler(c, message)
+	if err != nil {
+		m.handleError(err)
+		c.State = state.State
+		m.handleError(m.save(c))
+		return true
+	}
+
+	if next == EndConversation {
+		m.handleError(m.store.Delete(c.Key))
+		return true
+	}
+	if _, ok := m.states[next]; !ok {
+		m.handleError(fmt.Errorf("conversation state %q not declared", next))
+		c.State = state.State
+		m.handleError(m.save(c))
+		return true
+	}
+
+	c.State = next
+	m.handleError(m.enter(c, message))
+
+	return true
+}
+
+// conversation returns an empty Conversation for the sender of message.
+func (m *ConversationManager) conversation(message Message) *Conversation {
+	return &Conversation{
+		Bot:  m.bot,
+		Key:  ConversationKey{ChatID: message.Chat.ID, UserID: message.From.ID},
+		Data: make(map[string]string),
+	}
+}
+
+// enter saves the conversation in its new state and sends the prompt.
+func (m *ConversationManager) enter(c *Conversation, message Message) error {
+	if err := m.save(c); err != nil {
+		return err
+	}
+
+	prompt := m.states[c.State].prompt
+	if prompt.Text == "" {
+		return nil
+	}
+
+	var markup interface{} = ForceReply{ForceReply: true, Selective: true}
+	if len(prompt.Options) > 0 {
+		markup = ReplyKeyboardMarkup{
+			Keyboard:        prompt.Options,
+			ResizeKeyboard:  true,
+			OneTimeKeyboard: true,
+			Selective:       true,
+		}
+	}
+
+	return m.reply(message, prompt.Text, markup)
+}
+
+func (m *ConversationManager) save(c *Conversation) error {
+	state := ConversationState{State: c.State, Data: c.Data}
+	if m.Timeout > 0 {
+		state.Expires = time.Now().Add(m.Timeout)
+	}
+
+	return m.store.Set(c.Key, state)
+}
+
+// reply sends text in reply to message, so that selective markup is
+// only shown to its sender in groups.
+func (m *ConversationManager) reply(message Message, text string, markup interface{}) error {
+	config := NewMessage(message.Chat.ID, text)
+	config.ReplyToMessageID = message.MessageID
+	config.ReplyMarkup = markup
+
+	_, err := m.bot.Send(config)
+	return err
+}
+
+func (m *ConversationManager) isCancel(message Message) bool {
+	command := m.bot.Command(message)
+	if command == "" {
+		return false
+	}
+
+	for _, cancel := range m.CancelCommands {
+		if command == cancel {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handleError passes err to the ErrorHandler, if it isn't nil.
+func (m *ConversationManager) handleError(err error) {
+	if err == nil {
+		return
+	}
+
+	if m.ErrorHandler != nil {
+		m.ErrorHandler(err)
+		return
+	}
+
+	log.Printf("conversation: %v\n", err)
+}
@@ -0,0 +1,160 @@
+package tgbotapi_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/pho/telegram-bot-api"
+	"github.com/pho/telegram-bot-api/tgbotapitest"
+)
+
+func newConversationTest(t *testing.T) (*tgbotapitest.Server, *tgbotapi.ConversationManager, func(text string) bool) {
+	server := tgbotapitest.NewServer()
+
+	bot, err := server.NewBot()
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+
+	conversations := tgbotapi.NewConversationManager(bot, nil)
+	conversations.ErrorHandler = func(err error) {
+		t.Log(err)
+	}
+
+	conversations.HandleState("name", tgbotapi.Prompt{Text: "What's your name?"}, func(c *tgbotapi.Conversation, message tgbotapi.Message) (string, error) {
+		if message.Text == "" {
+			return "", errors.New("no name")
+		}
+		c.Data["name"] = message.Text
+		return "city", nil
+	})
+	conversations.HandleState("city", tgbotapi.Prompt{Text: "Your city?", Options: [][]string{{"Paris", "Berlin"}}}, func(c *tgbotapi.Conversation, message tgbotapi.Message) (string, error) {
+		if message.Text != "Paris" && message.Text != "Berlin" {
+			return "city", nil
+		}
+
+		_, err := c.Bot.SendMessage(tgbotapi.NewMessage(c.Key.ChatID, c.Data["name"]+" from "+message.Text))
+		return tgbotapi.EndConversation, err
+	})
+
+	id := 0
+	send := func(text string) bool {
+		id++
+		return conversations.HandleUpdate(tgbotapi.Update{
+			UpdateID: id,
+			Message: tgbotapi.Message{
+				MessageID: id,
+				From:      tgbotapi.User{ID: 7},
+				Chat:      tgbotapi.UserOrGroupChat{ID: -100},
+				Text:      text,
+			},
+		})
+	}
+
+	return server, conversations, send
+}
+
+func TestConversation(t *testing.T) {
+	server, conversations, send := newConversationTest(t)
+	defer server.Close()
+
+	if send("hi") {
+		t.Error("expected a message outside of a conversation not to be handled")
+	}
+
+	err := conversations.Begin(tgbotapi.Message{MessageID: 1, From: tgbotapi.User{ID: 7}, Chat: tgbotapi.UserOrGroupChat{ID: -100}}, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, text := range []string{"Ann", "London", "Paris"} {
+		if !send(text) {
+			t.Fatalf("expected %q to be handled by the conversation", text)
+		}
+	}
+
+	if send("hi again") {
+		t.Error("expected the conversation to have ended")
+	}
+
+	sent := server.Sent()
+	expected := []struct{ text, markup string }{
+		{"What's your name?", `"force_reply":true`},
+		{"Your city?", `"keyboard":[["Paris","Berlin"]]`},
+		{"Your city?", `"one_time_keyboard":true`},
+		{"Ann from Paris", ""},
+	}
+	if len(sent) != len(expected) {
+		t.Fatalf("expected %d messages, got %d", len(expected), len(sent))
+	}
+	for i, e := range expected {
+		if text := sent[i].Params.Get("text"); text != e.text {
+			t.Errorf("message %d: expected %q, got %q", i, e.text, text)
+		}
+		if markup := sent[i].Params.Get("reply_markup"); !strings.Contains(markup, e.markup) {
+			t.Errorf("message %d: expected markup with %s, got %s", i, e.markup, markup)
+		}
+	}
+	if markup := sent[0].Params.Get("reply_markup"); !strings.Contains(markup, `"selective":true`) {
+		t.Errorf("expected a selective prompt, got %s", markup)
+	}
+}
+
+func TestConversationCancelAndTimeout(t *testing.T) {
+	server, conversations, send := newConversationTest(t)
+	defer server.Close()
+
+	conversations.CancelCommands = []string{"cancel"}
+	conversations.CancelText = "Cancelled."
+	conversations.TimeoutText = "Too slow."
+
+	message := tgbotapi.Message{From: tgbotapi.User{ID: 7}, Chat: tgbotapi.UserOrGroupChat{ID: -100}}
+
+	conversations.Begin(message, "name")
+	if !send("/cancel@test_bot") {
+		t.Error("expected /cancel to be handled")
+	}
+	if send("Ann") {
+		t.Error("expected the conversation to be cancelled")
+	}
+
+	conversations.Timeout = time.Millisecond
+	conversations.Begin(message, "name")
+	time.Sleep(5 * time.Millisecond)
+	if send("Ann") {
+		t.Error("expected the conversation to have timed out")
+	}
+
+	var texts []string
+	for _, sent := range server.Sent() {
+		texts = append(texts, sent.Params.Get("text"))
+	}
+	expected := "What's your name?|Cancelled.|What's your name?|Too slow."
+	if strings.Join(texts, "|") != expected {
+		t.Errorf("expected messages %q, got %q", expected, texts)
+	}
+}
+
+func TestMemoryConversationStoreExpires(t *testing.T) {
+	store := tgbotapi.NewMemoryConversationStore()
+
+	abandoned := tgbotapi.ConversationKey{ChatID: 1, UserID: 1}
+	store.Set(abandoned, tgbotapi.ConversationState{State: "name", Expires: time.Now().Add(-time.Minute)})
+	active := tgbotapi.ConversationKey{ChatID: 1, UserID: 2}
+	store.Set(active, tgbotapi.ConversationState{State: "name"})
+
+	// Expired state is dropped as other conversations go on.
+	for i := 0; i < 100; i++ {
+		store.Set(tgbotapi.ConversationKey{ChatID: 2, UserID: i}, tgbotapi.ConversationState{State: "name"})
+	}
+
+	if _, ok, _ := store.Get(abandoned); ok {
+		t.Error("expected expired state to be dropped")
+	}
+	if _, ok, _ := store.Get(active); !ok {
+		t.Error("expected state without a timeout to be kept")
+	}
+}
@@ -1,24 +1,33 @@
-package tgbotapi
+package tgbotapi_test
 
 import (
 	"fmt"
 	"testing"
+
+	tgbotapi "github.com/pho/telegram-bot-api"
+	"github.com/pho/telegram-bot-api/tgbotapitest"
 )
 
 func TestEchobot(t *testing.T) {
 
+	// Start a fake Telegram server, so no token or network is needed
+	server := tgbotapitest.NewServer()
+	defer server.Close()
+
+	server.AddMessage(42, "Hello, bot!")
+
 	//Create a new bot
-	if b, err := NewBot("YourAwesomebotToken"); err == nil {
+	if b, err := server.NewBot(); err == nil {
 
 		//Get the updates channel
-		if c, err := b.GetUpdatesChan(UpdateConfig{Timeout: 60}); err == nil {
+		if c, err := b.GetUpdatesChan(tgbotapi.UpdateConfig{Timeout: 60}); err == nil {
 
 			fmt.Println("Waiting for updates...")
 			if e, ok := <-c; ok {
 				fmt.Println("Someone said:", e.Message.Text)
 
 				// Reply with the same message
-				if _, err := b.SendMessage(MessageConfig{ChatID: e.Message.Chat.ID, Text: e.Message.Text}); err != nil {
+				if _, err := b.SendMessage(tgbotapi.MessageConfig{ChatID: e.Message.Chat.ID, Text: e.Message.Text}); err != nil {
 					t.Error("Failed sending the message")
 				}
 
@@ -26,6 +35,8 @@ func TestEchobot(t *testing.T) {
 				t.Error("Failed getting any updates")
 			}
 
+			b.StopReceivingUpdates()
+
 		} else {
 			t.Error("Failed getting updates chan")
 		}
@@ -33,4 +44,8 @@ func TestEchobot(t *testing.T) {
 	} else {
 		t.Error("Failed creating the bot")
 	}
+
+	if sent := server.Sent(); len(sent) != 1 || sent[0].Message.Text != "Hello, bot!" || sent[0].Message.Chat.ID != 42 {
+		t.Errorf("Unexpected messages sent: %+v", sent)
+	}
 }
@@ -0,0 +1,128 @@
+package tgbotapi_test
+
+import (
+	"testing"
+
+	tgbotapi "github.com/pho/telegram-bot-api"
+	"github.com/pho/telegram-bot-api/tgbotapitest"
+)
+
+func TestEditMessage(t *testing.T) {
+	server := tgbotapitest.NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message, inline, err := bot.EditMessageText(tgbotapi.NewEditMessageText(42, 7, "edited"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inline || message.MessageID != 7 || message.Text != "edited" {
+		t.Errorf("unexpected message %+v", message)
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("a", "a")))
+	caption := tgbotapi.NewEditMessageCaption(42, 8, "new caption")
+	caption.ReplyMarkup = &markup
+	if message, inline, err = bot.EditMessageCaption(caption); err != nil {
+		t.Fatal(err)
+	}
+	if inline || message.MessageID != 8 {
+		t.Errorf("unexpected message %+v", message)
+	}
+
+	// Messages sent inline are identified by their inline message ID, and
+	// editing them returns true instead of the message.
+	message, inline, err = bot.EditMessageReplyMarkup(tgbotapi.EditMessageReplyMarkupConfig{InlineMessageID: "AAA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !inline || message.MessageID != 0 {
+		t.Errorf("expected no message for an inline edit, got %v, %+v", inline, message)
+	}
+
+	deleted, err := bot.DeleteMessage(tgbotapi.NewDeleteMessage(42, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !deleted {
+		t.Error("expected the message to be deleted")
+	}
+
+	sent := server.Sent()
+	if len(sent) != 4 {
+		t.Fatalf("expected 4 requests, got %d", len(sent))
+	}
+
+	expected := []struct {
+		method string
+		params map[string]string
+	}{
+		{"editMessageText", map[string]string{"chat_id": "42", "message_id": "7", "text": "edited", "reply_markup": "", "inline_message_id": ""}},
+		{"editMessageCaption", map[string]string{"chat_id": "42", "message_id": "8", "caption": "new caption", "reply_markup": `{"inline_keyboard":[[{"text":"a","callback_data":"a"}]]}`}},
+		{"editMessageReplyMarkup", map[string]string{"inline_message_id": "AAA", "chat_id": "", "message_id": ""}},
+		{"deleteMessage", map[string]string{"chat_id": "42", "message_id": "7"}},
+	}
+	for i, e := range expected {
+		if sent[i].Method != e.method {
+			t.Errorf("request %d: expected %s, got %s", i, e.method, sent[i].Method)
+		}
+		for key, value := range e.params {
+			if got := sent[i].Params.Get(key); got != value {
+				t.Errorf("%s: expected %s to be %q, got %q", e.method, key, value, got)
+			}
+		}
+	}
+}
+
+func TestEditMessageValidatesMarkup(t *testing.T) {
+	server := tgbotapitest.NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(tgbotapi.InlineKeyboardButton{Text: "does nothing"}))
+	if _, _, err := bot.EditMessageReplyMarkup(tgbotapi.NewEditMessageReplyMarkup(42, 7, markup)); err == nil {
+		t.Error("expected an invalid keyboard to be rejected")
+	}
+	if sent := server.Sent(); len(sent) != 0 {
+		t.Errorf("expected nothing to be sent, got %+v", sent)
+	}
+}
+
+func TestSendEditAndDelete(t *testing.T) {
+	server := tgbotapitest.NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Deletes and inline edits return true rather than a message, even
+	// through Send.
+	if _, err := bot.Send(tgbotapi.NewDeleteMessage(42, 7)); err != nil {
+		t.Error(err)
+	}
+	if _, err := bot.Send(tgbotapi.EditMessageTextConfig{InlineMessageID: "AAA", Text: "edited"}); err != nil {
+		t.Error(err)
+	}
+
+	message, err := bot.Send(tgbotapi.NewEditMessageText(42, 7, "edited"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.MessageID != 7 || message.Text != "edited" {
+		t.Errorf("unexpected message %+v", message)
+	}
+
+	if sent := server.Sent(); len(sent) != 3 {
+		t.Errorf("expected 3 requests, got %d", len(sent))
+	}
+}
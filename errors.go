@@ -0,0 +1,105 @@
+package tgbotapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrFileTooLarge is returned by DownloadFile for files larger than
+// the bot's MaxDownloadSize.
+var ErrFileTooLarge = errors.New("file is too large to download")
+
+// Errors returned by CallbackCodec.
+var (
+	ErrCallbackDataTooLarge = errors.New("callback data is too large")
+	ErrCallbackDataInvalid  = errors.New("callback data is invalid or was tampered with")
+	ErrCallbackDataExpired  = errors.New("callback data has expired")
+)
+
+// ErrUnterminatedQuote is returned by SplitArguments when a quote is left open.
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+// APIError is returned when the Telegram API reports a failed request.
+type APIError struct {
+	Code        int
+	Description string
+	ResponseParameters
+}
+
+// newAPIError creates an APIError from an unsuccessful APIResponse.
+func newAPIError(resp APIResponse) *APIError {
+	err := &APIError{
+		Code:        resp.ErrorCode,
+		Description: resp.Description,
+	}
+
+	if resp.Parameters != nil {
+		err.ResponseParameters = *resp.Parameters
+	}
+
+	return err
+}
+
+// Error returns the description given by Telegram.
+func (e *APIError) Error() string {
+	return e.Description
+}
+
+// hasCode reports whether err is an *APIError with the given code.
+func hasCode(err error, code int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// IsBadRequest reports whether err is a 400 Bad Request from the API.
+func IsBadRequest(err error) bool {
+	return hasCode(err, http.StatusBadRequest)
+}
+
+// IsUnauthorized reports whether err is a 401 Unauthorized from the API,
+// usually meaning the token has been revoked.
+func IsUnauthorized(err error) bool {
+	return hasCode(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is a 403 Forbidden from the API,
+// such as when the bot was blocked by the user or kicked from a group.
+func IsForbidden(err error) bool {
+	return hasCode(err, http.StatusForbidden)
+}
+
+// IsConflict reports whether err is a 409 Conflict from the API, such as
+// when another instance is polling for updates or a webhook is set.
+func IsConflict(err error) bool {
+	return hasCode(err, http.StatusConflict)
+}
+
+// IsTooManyRequests reports whether err is a 429 flood wait from the API.
+// The returned APIError's RetryAfter says how many seconds to wait.
+func IsTooManyRequests(err error) bool {
+	return hasCode(err, http.StatusTooManyRequests)
+}
+
+// IsChatNotFound reports whether err says the chat does not exist
+// or is not accessible to the bot.
+func IsChatNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusBadRequest &&
+		strings.Contains(strings.ToLower(apiErr.Description), "chat not found")
+}
+
+// IsMessageNotModified reports whether err says an edit was rejected
+// because it would leave the message as it was.
+func IsMessageNotModified(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusBadRequest &&
+		strings.Contains(strings.ToLower(apiErr.Description), "message is not modified")
+}
+
+// IsChatMigrated reports whether err says the group was upgraded to a
+// supergroup. The returned APIError's MigrateToChatID holds the new ID.
+func IsChatMigrated(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.MigrateToChatID != 0
+}
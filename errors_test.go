@@ -0,0 +1,61 @@
+package tgbotapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 7","parameters":{"retry_after":7}}`))
+	})
+
+	_, err := bot.SendMessage(NewMessage(1, "hello"))
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if apiErr.Code != 429 || apiErr.RetryAfter != 7 {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+	if !IsTooManyRequests(fmt.Errorf("wrapped: %w", err)) {
+		t.Error("expected IsTooManyRequests for a wrapped error")
+	}
+	if IsForbidden(err) {
+		t.Error("did not expect IsForbidden")
+	}
+}
+
+func TestAPIErrorNotJSON(t *testing.T) {
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html>Bad Gateway</html>"))
+	})
+
+	_, err := bot.GetMe()
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusBadGateway {
+		t.Errorf("expected a 502 *APIError, got %v", err)
+	}
+}
+
+func TestErrorPredicates(t *testing.T) {
+	notFound := &APIError{Code: 400, Description: "Bad Request: chat not found"}
+	if !IsChatNotFound(notFound) || !IsBadRequest(notFound) {
+		t.Error("expected chat not found")
+	}
+
+	migrated := &APIError{Code: 400, ResponseParameters: ResponseParameters{MigrateToChatID: -100123}}
+	if !IsChatMigrated(migrated) || IsChatNotFound(migrated) {
+		t.Error("expected chat migrated")
+	}
+
+	if IsForbidden(errors.New("Forbidden")) {
+		t.Error("plain errors should not match")
+	}
+}
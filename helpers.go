@@ -1,7 +1,9 @@
 package tgbotapi
 
 import (
+	"net/http"
 	"net/url"
+	"strings"
 )
 
 // NewMessage creates a new Message.
@@ -30,15 +32,15 @@ func NewForward(chatID int, fromChatID int, messageID int) ForwardConfig {
 }
 
 // NewPhotoUpload creates a new photo uploader.
-// This requires a file on the local filesystem to upload to Telegram.
 // Perhaps set a ChatAction of ChatUploadPhoto while processing.
 //
-// chatID is where to send it, filename is the path to the file.
-func NewPhotoUpload(chatID int, filename string) PhotoConfig {
+// chatID is where to send it, file is a path to a file on the local
+// filesystem, a FileBytes, or a FileReader.
+func NewPhotoUpload(chatID int, file interface{}) PhotoConfig {
 	return PhotoConfig{
 		ChatID:           chatID,
 		UseExistingPhoto: false,
-		FilePath:         filename,
+		File:             file,
 	}
 }
 
@@ -55,15 +57,15 @@ func NewPhotoShare(chatID int, fileID string) PhotoConfig {
 }
 
 // NewAudioUpload creates a new audio uploader.
-// This requires a file on the local filesystem to upload to Telegram.
 // Perhaps set a ChatAction of ChatRecordAudio or ChatUploadAudio while processing.
 //
-// chatID is where to send it, filename is the path to the file.
-func NewAudioUpload(chatID int, filename string) AudioConfig {
+// chatID is where to send it, file is a path to a file on the local
+// filesystem, a FileBytes, or a FileReader.
+func NewAudioUpload(chatID int, file interface{}) AudioConfig {
 	return AudioConfig{
 		ChatID:           chatID,
 		UseExistingAudio: false,
-		FilePath:         filename,
+		File:             file,
 	}
 }
 
@@ -80,15 +82,15 @@ func NewAudioShare(chatID int, fileID string) AudioConfig {
 }
 
 // NewDocumentUpload creates a new document uploader.
-// This requires a file on the local filesystem to upload to Telegram.
 // Perhaps set a ChatAction of ChatUploadDocument while processing.
 //
-// chatID is where to send it, filename is the path to the file.
-func NewDocumentUpload(chatID int, filename string) DocumentConfig {
+// chatID is where to send it, file is a path to a file on the local
+// filesystem, a FileBytes, or a FileReader.
+func NewDocumentUpload(chatID int, file interface{}) DocumentConfig {
 	return DocumentConfig{
 		ChatID:              chatID,
 		UseExistingDocument: false,
-		FilePath:            filename,
+		File:                file,
 	}
 }
 
@@ -105,14 +107,14 @@ func NewDocumentShare(chatID int, fileID string) DocumentConfig {
 }
 
 // NewStickerUpload creates a new sticker uploader.
-// This requires a file on the local filesystem to upload to Telegram.
 //
-// chatID is where to send it, filename is the path to the file.
-func NewStickerUpload(chatID int, filename string) StickerConfig {
+// chatID is where to send it, file is a path to a file on the local
+// filesystem, a FileBytes, or a FileReader.
+func NewStickerUpload(chatID int, file interface{}) StickerConfig {
 	return StickerConfig{
 		ChatID:             chatID,
 		UseExistingSticker: false,
-		FilePath:           filename,
+		File:               file,
 	}
 }
 
@@ -129,15 +131,15 @@ func NewStickerShare(chatID int, fileID string) StickerConfig {
 }
 
 // NewVideoUpload creates a new video uploader.
-// This requires a file on the local filesystem to upload to Telegram.
 // Perhaps set a ChatAction of ChatRecordVideo or ChatUploadVideo while processing.
 //
-// chatID is where to send it, filename is the path to the file.
-func NewVideoUpload(chatID int, filename string) VideoConfig {
+// chatID is where to send it, file is a path to a file on the local
+// filesystem, a FileBytes, or a FileReader.
+func NewVideoUpload(chatID int, file interface{}) VideoConfig {
 	return VideoConfig{
 		ChatID:           chatID,
 		UseExistingVideo: false,
-		FilePath:         filename,
+		File:             file,
 	}
 }
 
@@ -178,6 +180,107 @@ func NewChatAction(chatID int, action string) ChatActionConfig {
 	}
 }
 
+// NewInlineKeyboardMarkup creates an inline keyboard from rows of buttons.
+func NewInlineKeyboardMarkup(rows ...[]InlineKeyboardButton) InlineKeyboardMarkup {
+	return InlineKeyboardMarkup{
+		InlineKeyboard: rows,
+	}
+}
+
+// NewInlineKeyboardRow creates a row of buttons for an inline keyboard.
+func NewInlineKeyboardRow(buttons ...InlineKeyboardButton) []InlineKeyboardButton {
+	return buttons
+}
+
+// NewInlineKeyboardButtonData creates a button sending data in a
+// CallbackQuery when pressed.
+func NewInlineKeyboardButtonData(text, data string) InlineKeyboardButton {
+	return InlineKeyboardButton{
+		Text:         text,
+		CallbackData: data,
+	}
+}
+
+// NewInlineKeyboardButtonURL creates a button opening link when pressed.
+func NewInlineKeyboardButtonURL(text, link string) InlineKeyboardButton {
+	return InlineKeyboardButton{
+		Text: text,
+		URL:  link,
+	}
+}
+
+// NewInlineKeyboardButtonSwitch creates a button letting the user pick a
+// chat, and starting an inline query to the bot there with query.
+func NewInlineKeyboardButtonSwitch(text, query string) InlineKeyboardButton {
+	return InlineKeyboardButton{
+		Text:              text,
+		SwitchInlineQuery: &query,
+	}
+}
+
+// NewEditMessageText changes the text of a message.
+//
+// chatID and messageID identify the message, text is the new text.
+func NewEditMessageText(chatID int, messageID int, text string) EditMessageTextConfig {
+	return EditMessageTextConfig{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Text:      text,
+	}
+}
+
+// NewEditMessageReplyMarkup changes the inline keyboard of a message.
+//
+// chatID and messageID identify the message, markup is the new keyboard.
+func NewEditMessageReplyMarkup(chatID int, messageID int, markup InlineKeyboardMarkup) EditMessageReplyMarkupConfig {
+	return EditMessageReplyMarkupConfig{
+		ChatID:      chatID,
+		MessageID:   messageID,
+		ReplyMarkup: &markup,
+	}
+}
+
+// NewEditMessageCaption changes the caption of a message.
+//
+// chatID and messageID identify the message, caption is the new caption.
+func NewEditMessageCaption(chatID int, messageID int, caption string) EditMessageCaptionConfig {
+	return EditMessageCaptionConfig{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Caption:   caption,
+	}
+}
+
+// NewDeleteMessage deletes a message.
+//
+// chatID and messageID identify the message.
+func NewDeleteMessage(chatID int, messageID int) DeleteMessageConfig {
+	return DeleteMessageConfig{
+		ChatID:    chatID,
+		MessageID: messageID,
+	}
+}
+
+// NewCallback answers a CallbackQuery, showing text as a notification.
+//
+// id is the ID of the CallbackQuery, text may be empty.
+func NewCallback(id, text string) CallbackConfig {
+	return CallbackConfig{
+		CallbackQueryID: id,
+		Text:            text,
+		ShowAlert:       false,
+	}
+}
+
+// NewCallbackWithAlert answers a CallbackQuery, showing text as an alert.
+func NewCallbackWithAlert(id, text string) CallbackConfig {
+	return CallbackConfig{
+		CallbackQueryID: id,
+		Text:            text,
+		ShowAlert:       true,
+	}
+}
+
 // NewUserProfilePhotos gets user profile photos.
 //
 // userID is the ID of the user you wish to get profile photos from.
@@ -201,11 +304,41 @@ func NewUpdate(offset int) UpdateConfig {
 	}
 }
 
-// NewBotAPI creates a new BotAPI instance.
+// NewBot creates a new Bot instance.
 // Requires a token, provided by @BotFather on Telegram
 func NewBot(token string) (*Bot, error) {
+	return NewBotWithOptions(token, BotOptions{})
+}
+
+// NewBotWithOptions creates a new Bot instance using a custom API endpoint
+// and/or http.Client, such as a self-hosted Bot API server or one with
+// timeouts, a proxy or custom TLS settings.
+// Requires a token, provided by @BotFather on Telegram
+func NewBotWithOptions(token string, options BotOptions) (*Bot, error) {
+	endpoint := options.Endpoint
+	if endpoint == "" {
+		endpoint = APIEndpoint
+	}
+
+	client := options.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	maxDownload := options.MaxDownloadSize
+	if maxDownload <= 0 {
+		maxDownload = DefaultMaxDownloadSize
+	}
+
 	bot := &Bot{
-		token: token,
+		token:         token,
+		endpoint:      strings.TrimRight(endpoint, "/"),
+		client:        client,
+		retry:         options.Retry,
+		limiter:       options.RateLimiter,
+		maxDownload:   maxDownload,
+		middleware:    options.Middleware,
+		webhookSecret: options.WebhookSecretToken,
 	}
 
 	self, err := bot.GetMe()
@@ -234,11 +367,29 @@ func (b *Bot) UserName() string {
 // NewWebhook creates a new webhook.
 //
 // link is the url parsable link you wish to get the updates.
-func NewWebhook(link string) WebhookConfig {
-	u, _ := url.Parse(link)
+func NewWebhook(link string) (WebhookConfig, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return WebhookConfig{}, err
+	}
 
 	return WebhookConfig{
 		URL:   u,
 		Clear: false,
+	}, nil
+}
+
+// NewWebhookWithCert creates a new webhook with a self-signed certificate.
+//
+// link is the url you wish to get the updates, file is the public key
+// certificate as a path, FileBytes, or FileReader.
+func NewWebhookWithCert(link string, file interface{}) (WebhookConfig, error) {
+	config, err := NewWebhook(link)
+	if err != nil {
+		return WebhookConfig{}, err
 	}
+
+	config.Certificate = file
+
+	return config, nil
 }
@@ -0,0 +1,231 @@
+package tgbotapi
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Limits checked when validating keyboards.
+const (
+	// MaxButtonTextLength is the longest button text allowed, in characters.
+	MaxButtonTextLength = 64
+	// MaxCallbackDataSize is the largest CallbackData allowed, in bytes.
+	MaxCallbackDataSize = 64
+	// MaxKeyboardRows is the most rows a keyboard may have.
+	MaxKeyboardRows = 100
+)
+
+// Validate checks the keyboard is within Telegram's limits.
+// Messages with an invalid keyboard are not sent.
+func (markup ReplyKeyboardMarkup) Validate() error {
+	if err := validateRows(len(markup.Keyboard)); err != nil {
+		return err
+	}
+
+	for i, row := range markup.Keyboard {
+		for j, text := range row {
+			if err := validateButtonText(text); err != nil {
+				return fmt.Errorf("keyboard row %d button %d: %v", i+1, j+1, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the keyboard is within Telegram's limits, and that every
+// button does exactly one thing. Messages with an invalid keyboard are not
+// sent.
+func (markup InlineKeyboardMarkup) Validate() error {
+	if err := validateRows(len(markup.InlineKeyboard)); err != nil {
+		return err
+	}
+
+	for i, row := range markup.InlineKeyboard {
+		for j, button := range row {
+			if err := button.validate(); err != nil {
+				return fmt.Errorf("keyboard row %d button %d: %v", i+1, j+1, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (button InlineKeyboardButton) validate() error {
+	if err := validateButtonText(button.Text); err != nil {
+		return err
+	}
+
+	actions := 0
+	if button.URL != "" {
+		actions++
+	}
+	if button.CallbackData != "" {
+		actions++
+	}
+	if button.SwitchInlineQuery != nil {
+		actions++
+	}
+	if actions != 1 {
+		return errors.New("inline buttons need exactly one of a URL, callback data or switch inline query")
+	}
+
+	if len(button.CallbackData) > MaxCallbackDataSize {
+		return fmt.Errorf("callback data is %d bytes, more than %d", len(button.CallbackData), MaxCallbackDataSize)
+	}
+
+	return nil
+}
+
+func validateRows(rows int) error {
+	if rows == 0 {
+		return errors.New("keyboard has no buttons")
+	}
+	if rows > MaxKeyboardRows {
+		return fmt.Errorf("keyboard has %d rows, more than %d", rows, MaxKeyboardRows)
+	}
+
+	return nil
+}
+
+func validateButtonText(text string) error {
+	if text == "" {
+		return errors.New("button text is empty")
+	}
+	if n := utf8.RuneCountInString(text); n > MaxButtonTextLength {
+		return fmt.Errorf("button text is %d characters, more than %d", n, MaxButtonTextLength)
+	}
+
+	return nil
+}
+
+// KeyboardBuilder builds a reply or inline keyboard a button at a time.
+//
+//	markup, err := tgbotapi.NewKeyboard().Wrap(2).
+//		Button("Yes").Button("No").Button("Maybe").
+//		OneTime().ReplyMarkup()
+//
+// Reply keyboards can only have plain buttons, added with Button, while
+// inline keyboards need every button to do something when pressed.
+type KeyboardBuilder struct {
+	rows      [][]InlineKeyboardButton
+	wrap      int
+	resize    bool
+	oneTime   bool
+	selective bool
+}
+
+// NewKeyboard starts building a keyboard.
+func NewKeyboard() *KeyboardBuilder {
+	return &KeyboardBuilder{}
+}
+
+// Row starts a new row of buttons.
+func (k *KeyboardBuilder) Row() *KeyboardBuilder {
+	if len(k.rows) > 0 && len(k.rows[len(k.rows)-1]) > 0 {
+		k.rows = append(k.rows, nil)
+	}
+
+	return k
+}
+
+// Wrap starts a new row whenever one has n buttons. Rows are only
+// started by Row if n is zero.
+func (k *KeyboardBuilder) Wrap(n int) *KeyboardBuilder {
+	k.wrap = n
+	return k
+}
+
+// Button adds a plain button, which sends its text when pressed.
+// It can only be used in reply keyboards.
+func (k *KeyboardBuilder) Button(text string) *KeyboardBuilder {
+	return k.add(InlineKeyboardButton{Text: text})
+}
+
+// DataButton adds an inline button sending data in a CallbackQuery.
+func (k *KeyboardBuilder) DataButton(text, data string) *KeyboardBuilder {
+	return k.add(NewInlineKeyboardButtonData(text, data))
+}
+
+// URLButton adds an inline button opening link.
+func (k *KeyboardBuilder) URLButton(text, link string) *KeyboardBuilder {
+	return k.add(NewInlineKeyboardButtonURL(text, link))
+}
+
+// SwitchButton adds an inline button starting an inline query in another chat.
+func (k *KeyboardBuilder) SwitchButton(text, query string) *KeyboardBuilder {
+	return k.add(NewInlineKeyboardButtonSwitch(text, query))
+}
+
+// Resize asks clients to fit a reply keyboard to its buttons.
+func (k *KeyboardBuilder) Resize() *KeyboardBuilder {
+	k.resize = true
+	return k
+}
+
+// OneTime hides a reply keyboard once a button is pressed.
+func (k *KeyboardBuilder) OneTime() *KeyboardBuilder {
+	k.oneTime = true
+	return k
+}
+
+// Selective only shows a reply keyboard to users mentioned in the message,
+// and the sender of the message it replies to.
+func (k *KeyboardBuilder) Selective() *KeyboardBuilder {
+	k.selective = true
+	return k
+}
+
+func (k *KeyboardBuilder) add(button InlineKeyboardButton) *KeyboardBuilder {
+	if len(k.rows) == 0 {
+		k.rows = append(k.rows, nil)
+	}
+
+	last := len(k.rows) - 1
+	if k.wrap > 0 && len(k.rows[last]) >= k.wrap {
+		k.rows = append(k.rows, nil)
+		last++
+	}
+	k.rows[last] = append(k.rows[last], button)
+
+	return k
+}
+
+// ReplyMarkup returns the keyboard as a ReplyKeyboardMarkup, or an error
+// if it isn't valid or has inline buttons.
+func (k *KeyboardBuilder) ReplyMarkup() (ReplyKeyboardMarkup, error) {
+	markup := ReplyKeyboardMarkup{
+		Keyboard:        make([][]string, 0, len(k.rows)),
+		ResizeKeyboard:  k.resize,
+		OneTimeKeyboard: k.oneTime,
+		Selective:       k.selective,
+	}
+
+	for i, row := range k.rows {
+		texts := make([]string, len(row))
+		for j, button := range row {
+			if button.URL != "" || button.CallbackData != "" || button.SwitchInlineQuery != nil {
+				return markup, fmt.Errorf("keyboard row %d button %d: reply keyboards can only have plain buttons", i+1, j+1)
+			}
+			texts[j] = button.Text
+		}
+		markup.Keyboard = append(markup.Keyboard, texts)
+	}
+
+	return markup, markup.Validate()
+}
+
+// InlineMarkup returns the keyboard as an InlineKeyboardMarkup, or an
+// error if it isn't valid or has plain buttons.
+func (k *KeyboardBuilder) InlineMarkup() (InlineKeyboardMarkup, error) {
+	markup := InlineKeyboardMarkup{
+		InlineKeyboard: make([][]InlineKeyboardButton, 0, len(k.rows)),
+	}
+	for _, row := range k.rows {
+		markup.InlineKeyboard = append(markup.InlineKeyboard, append([]InlineKeyboardButton(nil), row...))
+	}
+
+	return markup, markup.Validate()
+}
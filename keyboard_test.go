@@ -0,0 +1,78 @@
+package tgbotapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestKeyboardBuilderReply(t *testing.T) {
+	markup, err := NewKeyboard().Wrap(2).
+		Button("1").Button("2").Button("3").
+		Row().Button("4").
+		Resize().OneTime().Selective().
+		ReplyMarkup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := json.Marshal(markup)
+	expected := `{"keyboard":[["1","2"],["3"],["4"]],"resize_keyboard":true,"one_time_keyboard":true,"selective":true}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	if _, err := NewKeyboard().Button("a").DataButton("b", "b").ReplyMarkup(); err == nil {
+		t.Error("expected an error for an inline button in a reply keyboard")
+	}
+}
+
+func TestKeyboardBuilderInline(t *testing.T) {
+	markup, err := NewKeyboard().
+		DataButton("Yes", "yes").DataButton("No", "no").
+		Row().URLButton("Help", "https://example.com").SwitchButton("Share", "").
+		InlineMarkup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := json.Marshal(markup)
+	expected := `{"inline_keyboard":[[{"text":"Yes","callback_data":"yes"},{"text":"No","callback_data":"no"}],` +
+		`[{"text":"Help","url":"https://example.com"},{"text":"Share","switch_inline_query":""}]]}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
+
+func TestKeyboardValidation(t *testing.T) {
+	tooManyRows := NewKeyboard().Wrap(1)
+	for i := 0; i <= MaxKeyboardRows; i++ {
+		tooManyRows.Button("a")
+	}
+
+	tests := map[string]*KeyboardBuilder{
+		"no buttons":     NewKeyboard(),
+		"more than 100":  tooManyRows,
+		"text is empty":  NewKeyboard().DataButton("", "a"),
+		"65 characters":  NewKeyboard().DataButton(strings.Repeat("é", 65), "a"),
+		"65 bytes":       NewKeyboard().DataButton("a", strings.Repeat("a", 65)),
+		"exactly one of": NewKeyboard().Button("a"),
+	}
+
+	for expected, k := range tests {
+		if _, err := k.InlineMarkup(); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected an error with %q, got %v", expected, err)
+		}
+	}
+
+	if _, err := NewKeyboard().DataButton(strings.Repeat("é", 64), strings.Repeat("a", 64)).InlineMarkup(); err != nil {
+		t.Errorf("expected a keyboard at the limits to be valid, got %v", err)
+	}
+
+	// Keyboards built by hand are checked before sending.
+	config := NewMessage(1, "hi")
+	config.ReplyMarkup = ReplyKeyboardMarkup{Keyboard: [][]string{{"a", ""}}}
+	if _, err := config.values(); err == nil || !strings.Contains(err.Error(), "row 1 button 2") {
+		t.Errorf("expected the message not to be sent, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package tgbotapi
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// MenuItem is an item in a Menu, shown as a button sending Data in a
+// CallbackQuery when pressed.
+type MenuItem struct {
+	Text string
+	Data string
+}
+
+// Menu shows a list of items a page at a time, as an inline keyboard with
+// buttons to move between pages:
+//
+//	[ Item 6 ]
+//	[ Item 7 ]
+//	[ ◀ | Page 2/7 | ▶ ]
+//
+// The page is kept in the callback data of the buttons, so menus need no
+// state on the server. Pass updates to HandleUpdate to turn pages, which
+// edits the menu's message in place. Presses on items are left for other
+// handlers.
+type Menu struct {
+	// Title is the text of the menu's message.
+	Title string
+	// PerPage is how many items are shown on a page. Defaults to 5.
+	PerPage int
+	// ErrorHandler is called with errors turning pages. By default they
+	// are logged.
+	ErrorHandler func(error)
+
+	bot    *Bot
+	prefix string
+	items  func(chatID int) []MenuItem
+}
+
+// NewMenu creates a Menu listing the items returned by items for a chat.
+// Menus in messages sent inline aren't in a chat, so items is called with
+// a chatID of 0 to turn their pages.
+//
+// id tells menus apart in callback data, so it should be short and unique
+// to the bot.
+func NewMenu(bot *Bot, id string, items func(chatID int) []MenuItem) *Menu {
+	return &Menu{
+		PerPage: 5,
+		bot:     bot,
+		prefix:  "menu:" + id + ":",
+		items:   items,
+	}
+}
+
+// Send sends the first page of the menu to a chat.
+func (m *Menu) Send(chatID int) (Message, error) {
+	markup, err := m.Markup(chatID, 0)
+	if err != nil {
+		return Message{}, err
+	}
+
+	config := NewMessage(chatID, m.Title)
+	config.ReplyMarkup = markup
+
+	return m.bot.Send(config)
+}
+
+// Markup returns the inline keyboard for a page of the menu, counting from
+// zero. Pages past the end show the last page, and a menu without items
+// shows a single empty page.
+func (m *Menu) Markup(chatID int, page int) (InlineKeyboardMarkup, error) {
+	items := m.items(chatID)
+	page, pages := m.clamp(page, len(items))
+
+	k := NewKeyboard().Wrap(1)
+	start := page * m.perPage()
+	for i := start; i < len(items) && i < start+m.perPage(); i++ {
+		k.DataButton(items[i].Text, items[i].Data)
+	}
+
+	// An empty menu still needs a button, so it shows just the page.
+	if pages > 1 || len(items) == 0 {
+		k.Wrap(0).Row()
+		if page > 0 {
+			k.DataButton("◀", m.prefix+strconv.Itoa(page-1))
+		}
+		k.DataButton(fmt.Sprintf("Page %d/%d", page+1, pages), m.prefix)
+		if page < pages-1 {
+			k.DataButton("▶", m.prefix+strconv.Itoa(page+1))
+		}
+	}
+
+	return k.InlineMarkup()
+}
+
+// HandleUpdate turns the page of the menu if the update is a press on one
+// of its buttons, returning true if it was.
+func (m *Menu) HandleUpdate(update Update) bool {
+	q := update.CallbackQuery
+	if q == nil || !strings.HasPrefix(q.Data, m.prefix) {
+		return false
+	}
+
+	m.handleError(m.bot.AnswerCallbackQuery(NewCallback(q.ID, "")))
+
+	// The button showing the page number does nothing.
+	data := strings.TrimPrefix(q.Data, m.prefix)
+	if data == "" || q.Message == nil && q.InlineMessageID == "" {
+		return true
+	}
+
+	page, err := strconv.Atoi(data)
+	if err != nil {
+		m.handleError(fmt.Errorf("menu: bad page in callback data %q", q.Data))
+		return true
+	}
+
+	// Messages sent inline aren't in a chat the bot can see.
+	chatID := 0
+	config := EditMessageReplyMarkupConfig{InlineMessageID: q.InlineMessageID}
+	if q.Message != nil {
+		chatID = q.Message.Chat.ID
+		config = EditMessageReplyMarkupConfig{ChatID: chatID, MessageID: q.Message.MessageID}
+	}
+
+	markup, err := m.Markup(chatID, page)
+	if err != nil {
+		m.handleError(err)
+		return true
+	}
+	config.ReplyMarkup = &markup
+
+	_, _, err = m.bot.EditMessageReplyMarkup(config)
+	if err != nil && !IsMessageNotModified(err) {
+		m.handleError(err)
+	}
+
+	return true
+}
+
+func (m *Menu) perPage() int {
+	if m.PerPage < 1 {
+		return 5
+	}
+
+	return m.PerPage
+}
+
+// clamp returns page within the pages of a menu with n items,
+// and the number of pages.
+func (m *Menu) clamp(page int, n int) (int, int) {
+	pages := (n + m.perPage() - 1) / m.perPage()
+	if pages < 1 {
+		pages = 1
+	}
+
+	if page >= pages {
+		page = pages - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+
+	return page, pages
+}
+
+// handleError passes err to the ErrorHandler, if it isn't nil.
+func (m *Menu) handleError(err error) {
+	if err == nil {
+		return
+	}
+
+	if m.ErrorHandler != nil {
+		m.ErrorHandler(err)
+		return
+	}
+
+	log.Printf("menu: %v\n", err)
+}
@@ -0,0 +1,182 @@
+package tgbotapi_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	tgbotapi "github.com/pho/telegram-bot-api"
+	"github.com/pho/telegram-bot-api/tgbotapitest"
+)
+
+func TestMenu(t *testing.T) {
+	server := tgbotapitest.NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	menu := tgbotapi.NewMenu(bot, "fruit", func(chatID int) []tgbotapi.MenuItem {
+		var items []tgbotapi.MenuItem
+		for i := 1; i <= 7; i++ {
+			items = append(items, tgbotapi.MenuItem{Text: fmt.Sprintf("Fruit %d", i), Data: fmt.Sprintf("fruit:%d", i)})
+		}
+		return items
+	})
+	menu.Title = "Pick a fruit"
+	menu.PerPage = 3
+	menu.ErrorHandler = func(err error) {
+		t.Error(err)
+	}
+
+	message, err := menu.Send(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	press := func(data string) bool {
+		return menu.HandleUpdate(tgbotapi.Update{
+			CallbackQuery: &tgbotapi.CallbackQuery{ID: "1", Message: &message, Data: data},
+		})
+	}
+
+	if press("fruit:2") {
+		t.Error("expected presses on items to be left to other handlers")
+	}
+	if !press("menu:fruit:1") || !press("menu:fruit:") || !press("menu:fruit:9") {
+		t.Error("expected presses on the menu to be handled")
+	}
+
+	type button struct {
+		Text string `json:"text"`
+		Data string `json:"callback_data"`
+	}
+	keyboard := func(sent tgbotapitest.SentMessage) string {
+		var markup struct {
+			InlineKeyboard [][]button `json:"inline_keyboard"`
+		}
+		json.Unmarshal([]byte(sent.Params.Get("reply_markup")), &markup)
+		return fmt.Sprint(markup.InlineKeyboard)
+	}
+
+	var edits []tgbotapitest.SentMessage
+	answers := 0
+	for _, sent := range server.Sent() {
+		switch sent.Method {
+		case "editMessageReplyMarkup":
+			edits = append(edits, sent)
+		case "answerCallbackQuery":
+			answers++
+		}
+	}
+
+	expected := "[[{Fruit 1 fruit:1}] [{Fruit 2 fruit:2}] [{Fruit 3 fruit:3}] [{Page 1/3 menu:fruit:} {▶ menu:fruit:1}]]"
+	if k := keyboard(server.Sent()[0]); k != expected {
+		t.Errorf("expected the first page\n%s, got\n%s", expected, k)
+	}
+
+	if answers != 3 {
+		t.Errorf("expected every press on the menu to be answered, got %d answers", answers)
+	}
+	if len(edits) != 2 {
+		t.Fatalf("expected 2 edits, got %d", len(edits))
+	}
+	if id := edits[0].Params.Get("message_id"); id != fmt.Sprint(message.MessageID) {
+		t.Errorf("expected the menu's message to be edited, got message %s", id)
+	}
+
+	expected = "[[{Fruit 4 fruit:4}] [{Fruit 5 fruit:5}] [{Fruit 6 fruit:6}] [{◀ menu:fruit:0} {Page 2/3 menu:fruit:} {▶ menu:fruit:2}]]"
+	if k := keyboard(edits[0]); k != expected {
+		t.Errorf("expected the second page\n%s, got\n%s", expected, k)
+	}
+	expected = "[[{Fruit 7 fruit:7}] [{◀ menu:fruit:1} {Page 3/3 menu:fruit:}]]"
+	if k := keyboard(edits[1]); k != expected {
+		t.Errorf("expected pages past the end to show the last page\n%s, got\n%s", expected, k)
+	}
+}
+
+func TestMenuEmpty(t *testing.T) {
+	server := tgbotapitest.NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var items []tgbotapi.MenuItem
+	menu := tgbotapi.NewMenu(bot, "list", func(chatID int) []tgbotapi.MenuItem {
+		return items
+	})
+	menu.Title = "Your list"
+	menu.ErrorHandler = func(err error) {
+		t.Error(err)
+	}
+
+	message, err := menu.Send(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"inline_keyboard":[[{"text":"Page 1/1","callback_data":"menu:list:"}]]}`
+	if markup := server.Sent()[0].Params.Get("reply_markup"); markup != expected {
+		t.Errorf("expected an empty page %s, got %s", expected, markup)
+	}
+
+	// A list that shrinks to nothing shows the empty page too.
+	items = []tgbotapi.MenuItem{{Text: "a", Data: "a"}}
+	menu.HandleUpdate(tgbotapi.Update{
+		CallbackQuery: &tgbotapi.CallbackQuery{ID: "1", Message: &message, Data: "menu:list:1"},
+	})
+	items = nil
+	menu.HandleUpdate(tgbotapi.Update{
+		CallbackQuery: &tgbotapi.CallbackQuery{ID: "2", Message: &message, Data: "menu:list:0"},
+	})
+
+	sent := server.Sent()
+	if markup := sent[len(sent)-1].Params.Get("reply_markup"); markup != expected {
+		t.Errorf("expected an empty page %s, got %s", expected, markup)
+	}
+}
+
+func TestMenuInline(t *testing.T) {
+	server := tgbotapitest.NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chatIDs []int
+	menu := tgbotapi.NewMenu(bot, "fruit", func(chatID int) []tgbotapi.MenuItem {
+		chatIDs = append(chatIDs, chatID)
+		return []tgbotapi.MenuItem{{Text: "Apple", Data: "apple"}, {Text: "Pear", Data: "pear"}}
+	})
+	menu.PerPage = 1
+	menu.ErrorHandler = func(err error) {
+		t.Error(err)
+	}
+
+	// Menus sent inline have no message, only an inline message ID.
+	menu.HandleUpdate(tgbotapi.Update{
+		CallbackQuery: &tgbotapi.CallbackQuery{ID: "1", InlineMessageID: "AAA", Data: "menu:fruit:1"},
+	})
+
+	sent := server.Sent()
+	if len(sent) != 2 || sent[1].Method != "editMessageReplyMarkup" {
+		t.Fatalf("expected the menu to be edited, got %+v", sent)
+	}
+	if id := sent[1].Params.Get("inline_message_id"); id != "AAA" {
+		t.Errorf("expected the inline message to be edited, got %q", id)
+	}
+	expected := `{"inline_keyboard":[[{"text":"Pear","callback_data":"pear"}],[{"text":"◀","callback_data":"menu:fruit:0"},{"text":"Page 2/2","callback_data":"menu:fruit:"}]]}`
+	if markup := sent[1].Params.Get("reply_markup"); markup != expected {
+		t.Errorf("expected the second page\n%s, got\n%s", expected, markup)
+	}
+	if len(chatIDs) != 1 || chatIDs[0] != 0 {
+		t.Errorf("expected the items for chat 0, got %v", chatIDs)
+	}
+}
@@ -2,8 +2,10 @@ package tgbotapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
@@ -13,8 +15,17 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// APIEndpoint is the default base URL of the Telegram Bot API.
+const APIEndpoint = "https://api.telegram.org"
+
+// DefaultMaxDownloadSize is the largest file DownloadFile accepts by default,
+// matching the largest file bots can download from Telegram.
+const DefaultMaxDownloadSize = 20 << 20
+
 // Constant values for ChatActions
 const (
 	ChatTyping         = "typing"
@@ -27,88 +38,6 @@ const (
 	ChatFindLocation   = "find_location"
 )
 
-// MessageConfig contains information about a SendMessage request.
-type MessageConfig struct {
-	ChatID                int
-	Text                  string
-	DisableWebPagePreview bool
-	ReplyToMessageID      int
-	ReplyMarkup           interface{}
-}
-
-// ForwardConfig contains infomation about a ForwardMessage request.
-type ForwardConfig struct {
-	ChatID     int
-	FromChatID int
-	MessageID  int
-}
-
-// PhotoConfig contains information about a SendPhoto request.
-type PhotoConfig struct {
-	ChatID           int
-	Caption          string
-	ReplyToMessageID int
-	ReplyMarkup      interface{}
-	UseExistingPhoto bool
-	FilePath         string
-	FileID           string
-}
-
-// AudioConfig contains information about a SendAudio request.
-type AudioConfig struct {
-	ChatID           int
-	ReplyToMessageID int
-	ReplyMarkup      interface{}
-	UseExistingAudio bool
-	FilePath         string
-	FileID           string
-}
-
-// DocumentConfig contains information about a SendDocument request.
-type DocumentConfig struct {
-	ChatID              int
-	ReplyToMessageID    int
-	ReplyMarkup         interface{}
-	UseExistingDocument bool
-	FilePath            string
-	FileID              string
-}
-
-// StickerConfig contains information about a SendSticker request.
-type StickerConfig struct {
-	ChatID             int
-	ReplyToMessageID   int
-	ReplyMarkup        interface{}
-	UseExistingSticker bool
-	FilePath           string
-	FileID             string
-}
-
-// VideoConfig contains information about a SendVideo request.
-type VideoConfig struct {
-	ChatID           int
-	ReplyToMessageID int
-	ReplyMarkup      interface{}
-	UseExistingVideo bool
-	FilePath         string
-	FileID           string
-}
-
-// LocationConfig contains information about a SendLocation request.
-type LocationConfig struct {
-	ChatID           int
-	Latitude         float64
-	Longitude        float64
-	ReplyToMessageID int
-	ReplyMarkup      interface{}
-}
-
-// ChatActionConfig contains information about a SendChatAction request.
-type ChatActionConfig struct {
-	ChatID int
-	Action string
-}
-
 // UserProfilePhotosConfig contains information about a GetUserProfilePhotos request.
 type UserProfilePhotosConfig struct {
 	UserID int
@@ -121,107 +50,337 @@ type UpdateConfig struct {
 	Offset  int
 	Limit   int
 	Timeout int
+	// ErrorHandler is called by GetUpdatesChan with every failed poll,
+	// including the one that stopped it.
+	ErrorHandler func(error)
 }
 
 // WebhookConfig contains information about a SetWebhook request.
 type WebhookConfig struct {
-	Clear bool
-	URL   *url.URL
+	Clear          bool
+	URL            *url.URL
+	Certificate    interface{}
+	MaxConnections int
+	AllowedUpdates []string
+	SecretToken    string
+}
+
+// baseURL returns the URL of the Bot API server in use.
+func (bot *Bot) baseURL() string {
+	if bot.endpoint == "" {
+		return APIEndpoint
+	}
+
+	return bot.endpoint
+}
+
+// methodURL returns the URL used to call a method with our token.
+func (bot *Bot) methodURL(endpoint string) string {
+	return bot.baseURL() + "/bot" + bot.token + "/" + endpoint
+}
+
+// httpClient returns the client requests should be sent with.
+func (bot *Bot) httpClient() *http.Client {
+	if bot.client == nil {
+		return http.DefaultClient
+	}
+
+	return bot.client
 }
 
 // MakeRequest makes a request to a specific endpoint with our token.
 // All requests are POSTs because Telegram doesn't care, and it's easier.
 func (bot *Bot) MakeRequest(endpoint string, params url.Values) (APIResponse, error) {
-	resp, err := http.PostForm("https://api.telegram.org/bot"+bot.token+"/"+endpoint, params)
-	defer resp.Body.Close()
-	if err != nil {
-		return APIResponse{}, err
-	}
+	return bot.MakeRequestContext(context.Background(), endpoint, params)
+}
 
-	bytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return APIResponse{}, err
-	}
+// MakeRequestContext is like MakeRequest but takes a context.
+// Cancelling ctx aborts the request and returns ctx.Err().
+func (bot *Bot) MakeRequestContext(ctx context.Context, endpoint string, params url.Values) (APIResponse, error) {
+	return bot.call(ctx, &Request{Endpoint: endpoint, Params: params})
+}
 
-	if bot.Debug {
-		log.Println(endpoint, string(bytes))
-	}
+// postForm sends a request as a url-encoded form.
+func (bot *Bot) postForm(ctx context.Context, endpoint string, params url.Values) (APIResponse, error) {
+	body := params.Encode()
 
-	var apiResp APIResponse
-	json.Unmarshal(bytes, &apiResp)
+	return bot.do(ctx, endpoint, limitedChatID(endpoint, params), func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", bot.methodURL(endpoint), strings.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
 
-	if !apiResp.Ok {
-		return APIResponse{}, errors.New(apiResp.Description)
-	}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	return apiResp, nil
+		return req, nil
+	})
 }
 
 // UploadFile makes a request to the API with a file.
 //
+// file may be a path to a file on the local filesystem, a FileBytes,
+// or a FileReader.
+//
 // Requires the parameter to hold the file not be in the params.
-func (bot *Bot) UploadFile(endpoint string, params map[string]string, fieldname string, filename string) (APIResponse, error) {
-	var b bytes.Buffer
-	w := multipart.NewWriter(&b)
+func (bot *Bot) UploadFile(endpoint string, params map[string]string, fieldname string, file interface{}) (APIResponse, error) {
+	return bot.UploadFileContext(context.Background(), endpoint, params, fieldname, file)
+}
 
-	pwd, err := os.Getwd()
-	if err != nil {
-		return ApiResponse{}, err
+// UploadFileContext is like UploadFile but takes a context.
+// Cancelling ctx aborts the upload and returns ctx.Err().
+func (bot *Bot) UploadFileContext(ctx context.Context, endpoint string, params map[string]string, fieldname string, file interface{}) (APIResponse, error) {
+	v := url.Values{}
+	for key, val := range params {
+		v.Add(key, val)
 	}
 
-	f, err := os.Open(filepath.FromSlash(pwd + "/" + filename))
-	if err != nil {
-		return ApiResponse{}, err
+	return bot.call(ctx, &Request{Endpoint: endpoint, Params: v, FileField: fieldname, File: file})
+}
+
+// postFile sends a request with a file as a multipart form,
+// reporting its progress to progress, if set.
+func (bot *Bot) postFile(ctx context.Context, endpoint string, params url.Values, fieldname string, file interface{}, progress ProgressFunc) (APIResponse, error) {
+	// A FileReader can only be sent again if it can be rewound.
+	var offset int64
+	if f, ok := file.(FileReader); ok {
+		if seeker, ok := f.Reader.(io.Seeker); ok {
+			offset, _ = seeker.Seek(0, io.SeekCurrent)
+		}
 	}
 
-	fw, err := w.CreateFormFile(fieldname, filename)
+	sent := false
+	return bot.do(ctx, endpoint, limitedChatID(endpoint, params), func() (*http.Request, error) {
+		if sent {
+			if err := rewindFile(file, offset); err != nil {
+				return nil, err
+			}
+		}
+		sent = true
+
+		name, r, size, err := openFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var body io.Reader = r
+		if progress != nil {
+			body = &progressReader{r: r, total: size, progress: progress}
+		}
+
+		// The body is streamed through a pipe instead of being built in memory.
+		pr, pw := io.Pipe()
+		w := multipart.NewWriter(pw)
+
+		req, err := http.NewRequestWithContext(ctx, "POST", bot.methodURL(endpoint), pr)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		if size >= 0 {
+			req.ContentLength = multipartLength(w.Boundary(), params, fieldname, name, size)
+		}
+
+		go func() {
+			defer r.Close()
+			pw.CloseWithError(writeMultipart(w, params, fieldname, name, body))
+		}()
+
+		return req, nil
+	})
+}
+
+// writeMultipart writes params followed by the file to w, and closes w.
+func writeMultipart(w *multipart.Writer, params url.Values, fieldname string, name string, r io.Reader) error {
+	for key, vals := range params {
+		for _, val := range vals {
+			if err := w.WriteField(key, val); err != nil {
+				return err
+			}
+		}
+	}
+
+	fw, err := w.CreateFormFile(fieldname, name)
 	if err != nil {
-		return APIResponse{}, err
+		return err
 	}
 
-	if _, err = io.Copy(fw, f); err != nil {
-		return APIResponse{}, err
+	if _, err = io.Copy(fw, r); err != nil {
+		return err
 	}
 
-	for key, val := range params {
-		if fw, err = w.CreateFormField(key); err != nil {
-			return APIResponse{}, err
+	return w.Close()
+}
+
+// multipartLength returns the length of the body writeMultipart
+// produces for a file of the given size.
+func multipartLength(boundary string, params url.Values, fieldname string, name string, size int64) int64 {
+	var c countingWriter
+	w := multipart.NewWriter(&c)
+	w.SetBoundary(boundary)
+	writeMultipart(w, params, fieldname, name, strings.NewReader(""))
+
+	return c.n + size
+}
+
+// countingWriter discards everything written to it, counting the bytes.
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// progressReader reports how much of a file has been read.
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.progress(p.sent, p.total)
+	}
+
+	return n, err
+}
+
+// rewindFile prepares a file for being uploaded again.
+func rewindFile(file interface{}, offset int64) error {
+	f, ok := file.(FileReader)
+	if !ok {
+		return nil
+	}
+
+	seeker, ok := f.Reader.(io.Seeker)
+	if !ok {
+		return errors.New("can't upload a FileReader again unless it is an io.Seeker")
+	}
+
+	_, err := seeker.Seek(offset, io.SeekStart)
+	return err
+}
+
+// openFile opens a file given to UploadFile, returning its name, contents,
+// and size, which is -1 if it isn't known.
+func openFile(file interface{}) (string, io.ReadCloser, int64, error) {
+	switch f := file.(type) {
+	case string:
+		r, err := os.Open(f)
+		if err != nil {
+			return "", nil, 0, err
 		}
 
-		if _, err = fw.Write([]byte(val)); err != nil {
-			return APIResponse{}, err
+		info, err := r.Stat()
+		if err != nil {
+			r.Close()
+			return "", nil, 0, err
 		}
-	}
 
-	w.Close()
+		return filepath.Base(f), r, info.Size(), nil
+	case FileBytes:
+		return f.Name, ioutil.NopCloser(bytes.NewReader(f.Bytes)), int64(len(f.Bytes)), nil
+	case FileReader:
+		if f.Reader == nil {
+			return "", nil, 0, errors.New("FileReader has no Reader")
+		}
 
-	req, err := http.NewRequest("POST", "https://api.telegram.org/bot"+bot.token+"/"+endpoint, &b)
-	if err != nil {
-		return APIResponse{}, err
+		// A zero Size is most likely unset rather than an empty file,
+		// so it is streamed without a length too.
+		size := f.Size
+		if size <= 0 {
+			size = -1
+		}
+
+		return f.Name, ioutil.NopCloser(f.Reader), size, nil
+	default:
+		return "", nil, 0, fmt.Errorf("unsupported file type %T", file)
 	}
+}
 
-	req.Header.Set("Content-Type", w.FormDataContentType())
+// do sends the requests made by newRequest, retrying failed attempts
+// according to the bot's RetryPolicy. Requests sending a message to
+// chatID are paced by the bot's RateLimiter; pass "" for other requests.
+func (bot *Bot) do(ctx context.Context, endpoint string, chatID string, newRequest func() (*http.Request, error)) (APIResponse, error) {
+	var lastResp APIResponse
+	var lastErr error
+
+	for attempt := 1; ; attempt++ {
+		if bot.limiter != nil && chatID != "" {
+			id, _ := strconv.Atoi(chatID)
+			if err := bot.limiter.Wait(ctx, id); err != nil {
+				return APIResponse{}, err
+			}
+		}
+
+		req, err := newRequest()
+		if err != nil {
+			if lastErr != nil {
+				// Report why we tried again, not why we couldn't.
+				return lastResp, lastErr
+			}
+			return APIResponse{}, err
+		}
+
+		resp, err := bot.send(ctx, endpoint, req)
+		if err == nil || !bot.retry.shouldRetry(attempt, err) {
+			return resp, err
+		}
+		lastResp, lastErr = resp, err
+
+		delay := bot.retry.delay(attempt, err)
+		if bot.Debug {
+			log.Printf("%s failed (attempt %d): %v, retrying in %v\n", endpoint, attempt, err, delay)
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return APIResponse{}, err
+		}
+	}
+}
 
-	client := &http.Client{}
-	res, err := client.Do(req)
+// send sends a prepared request and decodes the APIResponse.
+// Failed responses are returned along with an *APIError.
+func (bot *Bot) send(ctx context.Context, endpoint string, req *http.Request) (APIResponse, error) {
+	resp, err := bot.httpClient().Do(req)
 	if err != nil {
+		if ctx.Err() != nil {
+			return APIResponse{}, ctx.Err()
+		}
 		return APIResponse{}, err
 	}
+	defer resp.Body.Close()
 
-	bytes, err := ioutil.ReadAll(res.Body)
+	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
+		if ctx.Err() != nil {
+			return APIResponse{}, ctx.Err()
+		}
 		return APIResponse{}, err
 	}
 
 	if bot.Debug {
-		log.Println(string(bytes[:]))
+		log.Println(endpoint, string(data))
 	}
 
 	var apiResp APIResponse
-	json.Unmarshal(bytes, &apiResp)
+	if err := json.Unmarshal(data, &apiResp); err != nil {
+		// Not an API response at all, such as an error page from a proxy.
+		return APIResponse{}, &APIError{
+			Code:        resp.StatusCode,
+			Description: http.StatusText(resp.StatusCode),
+		}
+	}
 
 	if !apiResp.Ok {
-		return apiResp, errors.New(apiResp.Description)
+		return apiResp, newAPIError(apiResp)
 	}
 
 	return apiResp, nil
@@ -231,7 +390,12 @@ func (bot *Bot) UploadFile(endpoint string, params map[string]string, fieldname
 //
 // There are no parameters for this method.
 func (bot *Bot) GetMe() (User, error) {
-	resp, err := bot.MakeRequest("getMe", nil)
+	return bot.GetMeContext(context.Background())
+}
+
+// GetMeContext is like GetMe but takes a context for cancellation and deadlines.
+func (bot *Bot) GetMeContext(ctx context.Context) (User, error) {
+	resp, err := bot.MakeRequestContext(ctx, "getMe", nil)
 	if err != nil {
 		return User{}, err
 	}
@@ -248,487 +412,471 @@ func (bot *Bot) GetMe() (User, error) {
 	return user, nil
 }
 
-// SendMessage sends a Message to a chat.
-//
-// Requires ChatID and Text.
-// DisableWebPagePreview, ReplyToMessageID, and ReplyMarkup are optional.
-func (bot *Bot) SendMessage(config MessageConfig) (Message, error) {
-	v := url.Values{}
-	v.Add("chat_id", strconv.Itoa(config.ChatID))
-	v.Add("text", config.Text)
-	v.Add("disable_web_page_preview", strconv.FormatBool(config.DisableWebPagePreview))
-	if config.ReplyToMessageID != 0 {
-		v.Add("reply_to_message_id", strconv.Itoa(config.ReplyToMessageID))
-	}
-	if config.ReplyMarkup != nil {
-		data, err := json.Marshal(config.ReplyMarkup)
-		if err != nil {
-			return Message{}, err
-		}
-
-		v.Add("reply_markup", string(data))
-	}
-
-	resp, err := bot.MakeRequest("sendMessage", v)
+// Send sends a Chattable to a chat, uploading its file if it has one,
+// and returns the sent Message.
+// Requests that return true rather than a message, such as chat actions,
+// return an empty Message.
+func (bot *Bot) Send(c Chattable) (Message, error) {
+	return bot.SendContext(context.Background(), c)
+}
 
+// SendContext is like Send but takes a context for cancellation and deadlines.
+func (bot *Bot) SendContext(ctx context.Context, c Chattable) (Message, error) {
+	resp, err := bot.request(ctx, c)
 	if err != nil {
 		return Message{}, err
 	}
 
+	if string(resp.Result) == "true" {
+		return Message{}, nil
+	}
+
 	var message Message
-	json.Unmarshal(resp.Result, &message)
+	if err := json.Unmarshal(resp.Result, &message); err != nil {
+		return Message{}, err
+	}
 
 	if bot.Debug {
-		log.Printf("sendMessage req : %+v\n", v)
-		log.Printf("sendMessage resp: %+v\n", message)
+		log.Printf("%s resp: %+v\n", c.method(), message)
 	}
 
 	return message, nil
 }
 
-// ForwardMessage forwards a message from one chat to another.
-//
-// Requires ChatID (destionation), FromChatID (source), and MessageID.
-func (bot *Bot) ForwardMessage(config ForwardConfig) (Message, error) {
-	v := url.Values{}
-	v.Add("chat_id", strconv.Itoa(config.ChatID))
-	v.Add("from_chat_id", strconv.Itoa(config.FromChatID))
-	v.Add("message_id", strconv.Itoa(config.MessageID))
-
-	resp, err := bot.MakeRequest("forwardMessage", v)
+// request makes the request for a Chattable, returning the raw response.
+func (bot *Bot) request(ctx context.Context, c Chattable) (APIResponse, error) {
+	v, err := c.values()
 	if err != nil {
-		return Message{}, err
+		return APIResponse{}, err
 	}
 
-	var message Message
-	json.Unmarshal(resp.Result, &message)
-
 	if bot.Debug {
-		log.Printf("forwardMessage req : %+v\n", v)
-		log.Printf("forwardMessage resp: %+v\n", message)
+		log.Printf("%s req : %+v\n", c.method(), v)
 	}
 
-	return message, nil
-}
-
-// SendPhoto sends or uploads a photo to a chat.
-//
-// Requires ChatID and FileID OR FilePath.
-// Caption, ReplyToMessageID, and ReplyMarkup are optional.
-func (bot *Bot) SendPhoto(config PhotoConfig) (Message, error) {
-	if config.UseExistingPhoto {
-		v := url.Values{}
-		v.Add("chat_id", strconv.Itoa(config.ChatID))
-		v.Add("photo", config.FileID)
-		if config.Caption != "" {
-			v.Add("caption", config.Caption)
-		}
-		if config.ReplyToMessageID != 0 {
-			v.Add("reply_to_message_id", strconv.Itoa(config.ChatID))
-		}
-		if config.ReplyMarkup != nil {
-			data, err := json.Marshal(config.ReplyMarkup)
-			if err != nil {
-				return Message{}, err
-			}
-
-			v.Add("reply_markup", string(data))
-		}
-
-		resp, err := bot.MakeRequest("SendPhoto", v)
-		if err != nil {
-			return Message{}, err
-		}
+	req := &Request{Endpoint: c.method(), Params: v}
 
-		var message Message
-		json.Unmarshal(resp.Result, &message)
+	if f, ok := c.(Fileable); ok {
+		if u := f.upload(); u != nil {
+			req.FileField = u.field
+			req.File = u.file
+			req.progress = u.progress
 
-		if bot.Debug {
-			log.Printf("SendPhoto req : %+v\n", v)
-			log.Printf("SendPhoto resp: %+v\n", message)
+			if u.chatAction != "" {
+				chatID, _ := strconv.Atoi(v.Get("chat_id"))
+				defer bot.keepChatAction(ctx, chatID, u.chatAction)()
+			}
 		}
-
-		return message, nil
 	}
 
-	params := make(map[string]string)
-	params["chat_id"] = strconv.Itoa(config.ChatID)
-	if config.Caption != "" {
-		params["caption"] = config.Caption
-	}
-	if config.ReplyToMessageID != 0 {
-		params["reply_to_message_id"] = strconv.Itoa(config.ReplyToMessageID)
-	}
-	if config.ReplyMarkup != nil {
-		data, err := json.Marshal(config.ReplyMarkup)
-		if err != nil {
-			return Message{}, err
-		}
+	return bot.call(ctx, req)
+}
 
-		params["reply_markup"] = string(data)
-	}
+// SendMessage sends a Message to a chat.
+//
+// Requires ChatID and Text.
+// DisableWebPagePreview, ReplyToMessageID, and ReplyMarkup are optional.
+func (bot *Bot) SendMessage(config MessageConfig) (Message, error) {
+	return bot.SendContext(context.Background(), config)
+}
 
-	resp, err := bot.UploadFile("SendPhoto", params, "photo", config.FilePath)
-	if err != nil {
-		return Message{}, err
-	}
+// SendMessageContext is like SendMessage but takes a context for cancellation and deadlines.
+func (bot *Bot) SendMessageContext(ctx context.Context, config MessageConfig) (Message, error) {
+	return bot.SendContext(ctx, config)
+}
 
-	var message Message
-	json.Unmarshal(resp.Result, &message)
+// ForwardMessage forwards a message from one chat to another.
+//
+// Requires ChatID (destionation), FromChatID (source), and MessageID.
+func (bot *Bot) ForwardMessage(config ForwardConfig) (Message, error) {
+	return bot.SendContext(context.Background(), config)
+}
 
-	if bot.Debug {
-		log.Printf("SendPhoto resp: %+v\n", message)
-	}
+// ForwardMessageContext is like ForwardMessage but takes a context for cancellation and deadlines.
+func (bot *Bot) ForwardMessageContext(ctx context.Context, config ForwardConfig) (Message, error) {
+	return bot.SendContext(ctx, config)
+}
 
-	return message, nil
+// SendPhoto sends or uploads a photo to a chat.
+//
+// Requires ChatID and FileID OR File OR FilePath.
+// Caption, ReplyToMessageID, and ReplyMarkup are optional.
+// Progress and ChatAction are optional when uploading.
+func (bot *Bot) SendPhoto(config PhotoConfig) (Message, error) {
+	return bot.SendContext(context.Background(), config)
+}
+
+// SendPhotoContext is like SendPhoto but takes a context for cancellation and deadlines.
+func (bot *Bot) SendPhotoContext(ctx context.Context, config PhotoConfig) (Message, error) {
+	return bot.SendContext(ctx, config)
 }
 
 // SendAudio sends or uploads an audio clip to a chat.
 // If using a file, the file must be encoded as an .ogg with OPUS.
 //
-// Requires ChatID and FileID OR FilePath.
+// Requires ChatID and FileID OR File OR FilePath.
 // ReplyToMessageID and ReplyMarkup are optional.
+// Progress and ChatAction are optional when uploading.
 func (bot *Bot) SendAudio(config AudioConfig) (Message, error) {
-	if config.UseExistingAudio {
-		v := url.Values{}
-		v.Add("chat_id", strconv.Itoa(config.ChatID))
-		v.Add("audio", config.FileID)
-		if config.ReplyToMessageID != 0 {
-			v.Add("reply_to_message_id", strconv.Itoa(config.ReplyToMessageID))
-		}
-		if config.ReplyMarkup != nil {
-			data, err := json.Marshal(config.ReplyMarkup)
-			if err != nil {
-				return Message{}, err
-			}
-
-			v.Add("reply_markup", string(data))
-		}
-
-		resp, err := bot.MakeRequest("sendAudio", v)
-		if err != nil {
-			return Message{}, err
-		}
-
-		var message Message
-		json.Unmarshal(resp.Result, &message)
-
-		if bot.Debug {
-			log.Printf("sendAudio req : %+v\n", v)
-			log.Printf("sendAudio resp: %+v\n", message)
-		}
-
-		return message, nil
-	}
+	return bot.SendContext(context.Background(), config)
+}
 
-	params := make(map[string]string)
+// SendAudioContext is like SendAudio but takes a context for cancellation and deadlines.
+func (bot *Bot) SendAudioContext(ctx context.Context, config AudioConfig) (Message, error) {
+	return bot.SendContext(ctx, config)
+}
 
-	params["chat_id"] = strconv.Itoa(config.ChatID)
-	if config.ReplyToMessageID != 0 {
-		params["reply_to_message_id"] = strconv.Itoa(config.ReplyToMessageID)
-	}
-	if config.ReplyMarkup != nil {
-		data, err := json.Marshal(config.ReplyMarkup)
-		if err != nil {
-			return Message{}, err
-		}
+// SendDocument sends or uploads a document to a chat.
+//
+// Requires ChatID and FileID OR File OR FilePath.
+// ReplyToMessageID and ReplyMarkup are optional.
+// Progress and ChatAction are optional when uploading.
+func (bot *Bot) SendDocument(config DocumentConfig) (Message, error) {
+	return bot.SendContext(context.Background(), config)
+}
 
-		params["reply_markup"] = string(data)
-	}
+// SendDocumentContext is like SendDocument but takes a context for cancellation and deadlines.
+func (bot *Bot) SendDocumentContext(ctx context.Context, config DocumentConfig) (Message, error) {
+	return bot.SendContext(ctx, config)
+}
 
-	resp, err := bot.UploadFile("sendAudio", params, "audio", config.FilePath)
-	if err != nil {
-		return Message{}, err
-	}
+// SendSticker sends or uploads a sticker to a chat.
+//
+// Requires ChatID and FileID OR File OR FilePath.
+// ReplyToMessageID and ReplyMarkup are optional.
+// Progress and ChatAction are optional when uploading.
+func (bot *Bot) SendSticker(config StickerConfig) (Message, error) {
+	return bot.SendContext(context.Background(), config)
+}
 
-	var message Message
-	json.Unmarshal(resp.Result, &message)
+// SendStickerContext is like SendSticker but takes a context for cancellation and deadlines.
+func (bot *Bot) SendStickerContext(ctx context.Context, config StickerConfig) (Message, error) {
+	return bot.SendContext(ctx, config)
+}
 
-	if bot.Debug {
-		log.Printf("sendAudio resp: %+v\n", message)
-	}
+// SendVideo sends or uploads a video to a chat.
+//
+// Requires ChatID and FileID OR File OR FilePath.
+// ReplyToMessageID and ReplyMarkup are optional.
+// Progress and ChatAction are optional when uploading.
+func (bot *Bot) SendVideo(config VideoConfig) (Message, error) {
+	return bot.SendContext(context.Background(), config)
+}
 
-	return message, nil
+// SendVideoContext is like SendVideo but takes a context for cancellation and deadlines.
+func (bot *Bot) SendVideoContext(ctx context.Context, config VideoConfig) (Message, error) {
+	return bot.SendContext(ctx, config)
 }
 
-// SendDocument sends or uploads a document to a chat.
+// SendLocation sends a location to a chat.
 //
-// Requires ChatID and FileID OR FilePath.
+// Requires ChatID, Latitude, and Longitude.
 // ReplyToMessageID and ReplyMarkup are optional.
-func (bot *Bot) SendDocument(config DocumentConfig) (Message, error) {
-	if config.UseExistingDocument {
-		v := url.Values{}
-		v.Add("chat_id", strconv.Itoa(config.ChatID))
-		v.Add("document", config.FileID)
-		if config.ReplyToMessageID != 0 {
-			v.Add("reply_to_message_id", strconv.Itoa(config.ReplyToMessageID))
-		}
-		if config.ReplyMarkup != nil {
-			data, err := json.Marshal(config.ReplyMarkup)
-			if err != nil {
-				return Message{}, err
-			}
+func (bot *Bot) SendLocation(config LocationConfig) (Message, error) {
+	return bot.SendContext(context.Background(), config)
+}
 
-			v.Add("reply_markup", string(data))
-		}
+// SendLocationContext is like SendLocation but takes a context for cancellation and deadlines.
+func (bot *Bot) SendLocationContext(ctx context.Context, config LocationConfig) (Message, error) {
+	return bot.SendContext(ctx, config)
+}
 
-		resp, err := bot.MakeRequest("sendDocument", v)
-		if err != nil {
-			return Message{}, err
-		}
+// EditMessageText changes the text of a message the bot sent.
+//
+// Requires Text, and either ChatID and MessageID, or InlineMessageID.
+// DisableWebPagePreview and ReplyMarkup are optional; leaving ReplyMarkup
+// nil removes any inline keyboard.
+// The edited Message is returned for messages in chats. Messages sent
+// inline are not returned; the bool is true for them instead.
+func (bot *Bot) EditMessageText(config EditMessageTextConfig) (Message, bool, error) {
+	return bot.edit(context.Background(), config)
+}
 
-		var message Message
-		json.Unmarshal(resp.Result, &message)
+// EditMessageTextContext is like EditMessageText but takes a context for cancellation and deadlines.
+func (bot *Bot) EditMessageTextContext(ctx context.Context, config EditMessageTextConfig) (Message, bool, error) {
+	return bot.edit(ctx, config)
+}
 
-		if bot.Debug {
-			log.Printf("sendDocument req : %+v\n", v)
-			log.Printf("sendDocument resp: %+v\n", message)
-		}
+// EditMessageCaption changes the caption of a message the bot sent.
+//
+// Requires either ChatID and MessageID, or InlineMessageID.
+// Caption and ReplyMarkup are optional; leaving ReplyMarkup nil removes
+// any inline keyboard.
+// The edited Message is returned for messages in chats. Messages sent
+// inline are not returned; the bool is true for them instead.
+func (bot *Bot) EditMessageCaption(config EditMessageCaptionConfig) (Message, bool, error) {
+	return bot.edit(context.Background(), config)
+}
 
-		return message, nil
-	}
+// EditMessageCaptionContext is like EditMessageCaption but takes a context for cancellation and deadlines.
+func (bot *Bot) EditMessageCaptionContext(ctx context.Context, config EditMessageCaptionConfig) (Message, bool, error) {
+	return bot.edit(ctx, config)
+}
 
-	params := make(map[string]string)
+// EditMessageReplyMarkup changes the inline keyboard of a message the bot sent.
+//
+// Requires either ChatID and MessageID, or InlineMessageID.
+// ReplyMarkup is optional; leaving it nil removes the inline keyboard.
+// The edited Message is returned for messages in chats. Messages sent
+// inline are not returned; the bool is true for them instead.
+func (bot *Bot) EditMessageReplyMarkup(config EditMessageReplyMarkupConfig) (Message, bool, error) {
+	return bot.edit(context.Background(), config)
+}
 
-	params["chat_id"] = strconv.Itoa(config.ChatID)
-	if config.ReplyToMessageID != 0 {
-		params["reply_to_message_id"] = strconv.Itoa(config.ReplyToMessageID)
-	}
-	if config.ReplyMarkup != nil {
-		data, err := json.Marshal(config.ReplyMarkup)
-		if err != nil {
-			return Message{}, err
-		}
+// EditMessageReplyMarkupContext is like EditMessageReplyMarkup but takes a context for cancellation and deadlines.
+func (bot *Bot) EditMessageReplyMarkupContext(ctx context.Context, config EditMessageReplyMarkupConfig) (Message, bool, error) {
+	return bot.edit(ctx, config)
+}
 
-		params["reply_markup"] = string(data)
+// edit makes an edit request. The API returns the edited message, or true
+// if the message was sent inline.
+func (bot *Bot) edit(ctx context.Context, c Chattable) (Message, bool, error) {
+	resp, err := bot.request(ctx, c)
+	if err != nil {
+		return Message{}, false, err
 	}
 
-	resp, err := bot.UploadFile("sendDocument", params, "document", config.FilePath)
-	if err != nil {
-		return Message{}, err
+	if string(resp.Result) == "true" {
+		return Message{}, true, nil
 	}
 
 	var message Message
-	json.Unmarshal(resp.Result, &message)
+	if err := json.Unmarshal(resp.Result, &message); err != nil {
+		return Message{}, false, err
+	}
 
 	if bot.Debug {
-		log.Printf("sendDocument resp: %+v\n", message)
+		log.Printf("%s resp: %+v\n", c.method(), message)
 	}
 
-	return message, nil
+	return message, false, nil
 }
 
-// SendSticker sends or uploads a sticker to a chat.
+// DeleteMessage deletes a message, returning true if it was deleted.
 //
-// Requires ChatID and FileID OR FilePath.
-// ReplyToMessageID and ReplyMarkup are optional.
-func (bot *Bot) SendSticker(config StickerConfig) (Message, error) {
-	if config.UseExistingSticker {
-		v := url.Values{}
-		v.Add("chat_id", strconv.Itoa(config.ChatID))
-		v.Add("sticker", config.FileID)
-		if config.ReplyToMessageID != 0 {
-			v.Add("reply_to_message_id", strconv.Itoa(config.ReplyToMessageID))
-		}
-		if config.ReplyMarkup != nil {
-			data, err := json.Marshal(config.ReplyMarkup)
-			if err != nil {
-				return Message{}, err
-			}
-
-			v.Add("reply_markup", string(data))
-		}
-
-		resp, err := bot.MakeRequest("sendSticker", v)
-		if err != nil {
-			return Message{}, err
-		}
-
-		var message Message
-		json.Unmarshal(resp.Result, &message)
-
-		if bot.Debug {
-			log.Printf("sendSticker req : %+v\n", v)
-			log.Printf("sendSticker resp: %+v\n", message)
-		}
+// Requires ChatID and MessageID.
+func (bot *Bot) DeleteMessage(config DeleteMessageConfig) (bool, error) {
+	return bot.DeleteMessageContext(context.Background(), config)
+}
 
-		return message, nil
+// DeleteMessageContext is like DeleteMessage but takes a context for cancellation and deadlines.
+func (bot *Bot) DeleteMessageContext(ctx context.Context, config DeleteMessageConfig) (bool, error) {
+	resp, err := bot.request(ctx, config)
+	if err != nil {
+		return false, err
 	}
 
-	params := make(map[string]string)
-
-	params["chat_id"] = strconv.Itoa(config.ChatID)
-	if config.ReplyToMessageID != 0 {
-		params["reply_to_message_id"] = strconv.Itoa(config.ReplyToMessageID)
+	var deleted bool
+	if err := json.Unmarshal(resp.Result, &deleted); err != nil {
+		return false, err
 	}
-	if config.ReplyMarkup != nil {
-		data, err := json.Marshal(config.ReplyMarkup)
-		if err != nil {
-			return Message{}, err
-		}
 
-		params["reply_markup"] = string(data)
-	}
+	return deleted, nil
+}
 
-	resp, err := bot.UploadFile("sendSticker", params, "sticker", config.FilePath)
-	if err != nil {
-		return Message{}, err
-	}
+// SendChatAction sets a current action in a chat.
+//
+// Requires ChatID and a valid Action (see Chat constants).
+func (bot *Bot) SendChatAction(config ChatActionConfig) error {
+	return bot.SendChatActionContext(context.Background(), config)
+}
 
-	var message Message
-	json.Unmarshal(resp.Result, &message)
+// SendChatActionContext is like SendChatAction but takes a context for cancellation and deadlines.
+func (bot *Bot) SendChatActionContext(ctx context.Context, config ChatActionConfig) error {
+	_, err := bot.request(ctx, config)
+	return err
+}
 
-	if bot.Debug {
-		log.Printf("sendSticker resp: %+v\n", message)
-	}
+// AnswerCallbackQuery responds to a CallbackQuery, which stops the
+// progress bar on the pressed button.
+//
+// Requires CallbackQueryID.
+// Text and ShowAlert are optional, showing a notification or alert.
+func (bot *Bot) AnswerCallbackQuery(config CallbackConfig) error {
+	return bot.AnswerCallbackQueryContext(context.Background(), config)
+}
 
-	return message, nil
+// AnswerCallbackQueryContext is like AnswerCallbackQuery but takes a context for cancellation and deadlines.
+func (bot *Bot) AnswerCallbackQueryContext(ctx context.Context, config CallbackConfig) error {
+	_, err := bot.request(ctx, config)
+	return err
 }
 
-// SendVideo sends or uploads a video to a chat.
-//
-// Requires ChatID and FileID OR FilePath.
-// ReplyToMessageID and ReplyMarkup are optional.
-func (bot *Bot) SendVideo(config VideoConfig) (Message, error) {
-	if config.UseExistingVideo {
-		v := url.Values{}
-		v.Add("chat_id", strconv.Itoa(config.ChatID))
-		v.Add("video", config.FileID)
-		if config.ReplyToMessageID != 0 {
-			v.Add("reply_to_message_id", strconv.Itoa(config.ReplyToMessageID))
-		}
-		if config.ReplyMarkup != nil {
-			data, err := json.Marshal(config.ReplyMarkup)
-			if err != nil {
-				return Message{}, err
-			}
+// chatActionInterval is how often keepChatAction repeats an action,
+// which Telegram shows for five seconds.
+const chatActionInterval = 4 * time.Second
 
-			v.Add("reply_markup", string(data))
-		}
+// keepChatAction sends action to a chat until the returned func is called.
+// Errors are ignored, as the action is only cosmetic. Chat actions don't
+// count against the bot's RateLimiter, so they never hold up the upload
+// or the messages sent after it.
+func (bot *Bot) keepChatAction(ctx context.Context, chatID int, action string) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
 
-		resp, err := bot.MakeRequest("sendVideo", v)
-		if err != nil {
-			return Message{}, err
-		}
+	go func() {
+		defer close(done)
 
-		var message Message
-		json.Unmarshal(resp.Result, &message)
+		ticker := time.NewTicker(chatActionInterval)
+		defer ticker.Stop()
 
-		if bot.Debug {
-			log.Printf("sendVideo req : %+v\n", v)
-			log.Printf("sendVideo resp: %+v\n", message)
+		for {
+			bot.SendChatActionContext(ctx, NewChatAction(chatID, action))
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
 		}
+	}()
 
-		return message, nil
+	return func() {
+		cancel()
+		<-done
 	}
+}
 
-	params := make(map[string]string)
+// GetUserProfilePhotos gets a user's profile photos.
+//
+// Requires UserID.
+// Offset and Limit are optional.
+func (bot *Bot) GetUserProfilePhotos(config UserProfilePhotosConfig) (UserProfilePhotos, error) {
+	return bot.GetUserProfilePhotosContext(context.Background(), config)
+}
 
-	params["chat_id"] = strconv.Itoa(config.ChatID)
-	if config.ReplyToMessageID != 0 {
-		params["reply_to_message_id"] = strconv.Itoa(config.ReplyToMessageID)
+// GetUserProfilePhotosContext is like GetUserProfilePhotos but takes a context for cancellation and deadlines.
+func (bot *Bot) GetUserProfilePhotosContext(ctx context.Context, config UserProfilePhotosConfig) (UserProfilePhotos, error) {
+	v := url.Values{}
+	v.Add("user_id", strconv.Itoa(config.UserID))
+	if config.Offset != 0 {
+		v.Add("offset", strconv.Itoa(config.Offset))
 	}
-	if config.ReplyMarkup != nil {
-		data, err := json.Marshal(config.ReplyMarkup)
-		if err != nil {
-			return Message{}, err
-		}
-
-		params["reply_markup"] = string(data)
+	if config.Limit != 0 {
+		v.Add("limit", strconv.Itoa(config.Limit))
 	}
 
-	resp, err := bot.UploadFile("sendVideo", params, "video", config.FilePath)
+	resp, err := bot.MakeRequestContext(ctx, "getUserProfilePhotos", v)
 	if err != nil {
-		return Message{}, err
+		return UserProfilePhotos{}, err
 	}
 
-	var message Message
-	json.Unmarshal(resp.Result, &message)
+	var profilePhotos UserProfilePhotos
+	json.Unmarshal(resp.Result, &profilePhotos)
 
 	if bot.Debug {
-		log.Printf("sendVideo resp: %+v\n", message)
+		log.Printf("getUserProfilePhotos req : %+v\n", v)
+		log.Printf("getUserProfilePhotos resp: %+v\n", profilePhotos)
 	}
 
-	return message, nil
+	return profilePhotos, nil
 }
 
-// SendLocation sends a location to a chat.
+// GetFile gets information about a file, including the FilePath
+// needed to download it.
 //
-// Requires ChatID, Latitude, and Longitude.
-// ReplyToMessageID and ReplyMarkup are optional.
-func (bot *Bot) SendLocation(config LocationConfig) (Message, error) {
-	v := url.Values{}
-	v.Add("chat_id", strconv.Itoa(config.ChatID))
-	v.Add("latitude", strconv.FormatFloat(config.Latitude, 'f', 6, 64))
-	v.Add("longitude", strconv.FormatFloat(config.Longitude, 'f', 6, 64))
-	if config.ReplyToMessageID != 0 {
-		v.Add("reply_to_message_id", strconv.Itoa(config.ReplyToMessageID))
-	}
-	if config.ReplyMarkup != nil {
-		data, err := json.Marshal(config.ReplyMarkup)
-		if err != nil {
-			return Message{}, err
-		}
+// Requires fileID, such as a Message's Document.FileID.
+func (bot *Bot) GetFile(fileID string) (File, error) {
+	return bot.GetFileContext(context.Background(), fileID)
+}
 
-		v.Add("reply_markup", string(data))
-	}
+// GetFileContext is like GetFile but takes a context for cancellation and deadlines.
+func (bot *Bot) GetFileContext(ctx context.Context, fileID string) (File, error) {
+	v := url.Values{}
+	v.Add("file_id", fileID)
 
-	resp, err := bot.MakeRequest("sendLocation", v)
+	resp, err := bot.MakeRequestContext(ctx, "getFile", v)
 	if err != nil {
-		return Message{}, err
+		return File{}, err
 	}
 
-	var message Message
-	json.Unmarshal(resp.Result, &message)
+	var file File
+	if err := json.Unmarshal(resp.Result, &file); err != nil {
+		return File{}, err
+	}
 
 	if bot.Debug {
-		log.Printf("sendLocation req : %+v\n", v)
-		log.Printf("sendLocation resp: %+v\n", message)
+		log.Printf("getFile req : %+v\n", v)
+		log.Printf("getFile resp: %+v\n", file)
 	}
 
-	return message, nil
+	return file, nil
 }
 
-// SendChatAction sets a current action in a chat.
-//
-// Requires ChatID and a valid Action (see Chat constants).
-func (bot *Bot) SendChatAction(config ChatActionConfig) error {
-	v := url.Values{}
-	v.Add("chat_id", strconv.Itoa(config.ChatID))
-	v.Add("action", config.Action)
-
-	_, err := bot.MakeRequest("sendChatAction", v)
-	return err
+// FileURL returns the URL to download a file from.
+// It contains the bot's token, so don't share it.
+func (bot *Bot) FileURL(file File) string {
+	return bot.baseURL() + "/file/bot" + bot.token + "/" + file.FilePath
 }
 
-// GetUserProfilePhotos gets a user's profile photos.
+// DownloadFile downloads a file to w, returning the number of bytes written.
+// Files larger than the bot's MaxDownloadSize fail with ErrFileTooLarge.
+// Files whose size is only found out while downloading leave up to
+// MaxDownloadSize bytes in w when they fail, but never more.
 //
-// Requires UserID.
-// Offset and Limit are optional.
-func (bot *Bot) GetUserProfilePhotos(config UserProfilePhotosConfig) (UserProfilePhotos, error) {
-	v := url.Values{}
-	v.Add("user_id", strconv.Itoa(config.UserID))
-	if config.Offset != 0 {
-		v.Add("offset", strconv.Itoa(config.Offset))
+// Requires fileID, such as a Message's Document.FileID.
+func (bot *Bot) DownloadFile(fileID string, w io.Writer) (int64, error) {
+	return bot.DownloadFileContext(context.Background(), fileID, w)
+}
+
+// DownloadFileContext is like DownloadFile but takes a context for cancellation and deadlines.
+func (bot *Bot) DownloadFileContext(ctx context.Context, fileID string, w io.Writer) (int64, error) {
+	limit := bot.maxDownload
+	if limit <= 0 {
+		limit = DefaultMaxDownloadSize
 	}
-	if config.Limit != 0 {
-		v.Add("limit", strconv.Itoa(config.Limit))
+
+	file, err := bot.GetFileContext(ctx, fileID)
+	if err != nil {
+		return 0, err
 	}
 
-	resp, err := bot.MakeRequest("getUserProfilePhotos", v)
+	if int64(file.FileSize) > limit {
+		return 0, ErrFileTooLarge
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", bot.FileURL(file), nil)
 	if err != nil {
-		return UserProfilePhotos{}, err
+		return 0, err
 	}
 
-	var profilePhotos UserProfilePhotos
-	json.Unmarshal(resp.Result, &profilePhotos)
+	resp, err := bot.httpClient().Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
 
-	if bot.Debug {
-		log.Printf("getUserProfilePhotos req : %+v\n", v)
-		log.Printf("getUserProfilePhotos resp: %+v\n", profilePhotos)
+	if resp.StatusCode != http.StatusOK {
+		return 0, &APIError{
+			Code:        resp.StatusCode,
+			Description: http.StatusText(resp.StatusCode),
+		}
 	}
 
-	return profilePhotos, nil
+	if resp.ContentLength > limit {
+		return 0, ErrFileTooLarge
+	}
+
+	n, err := io.Copy(w, io.LimitReader(resp.Body, limit))
+	if err != nil {
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+		return n, err
+	}
+
+	// Anything left past the limit means the file is too large. It's read
+	// here rather than copied, so nothing past the limit reaches w.
+	if n == limit {
+		if _, err := io.ReadFull(resp.Body, make([]byte, 1)); err == nil {
+			return n, ErrFileTooLarge
+		}
+	}
+
+	return n, nil
 }
 
 // GetUpdates fetches updates.
@@ -738,6 +886,11 @@ func (bot *Bot) GetUserProfilePhotos(config UserProfilePhotosConfig) (UserProfil
 // To not get old items, set Offset to one higher than the previous item.
 // Set Timeout to a large number to reduce requests and get responses instantly.
 func (bot *Bot) GetUpdates(config UpdateConfig) ([]Update, error) {
+	return bot.GetUpdatesContext(context.Background(), config)
+}
+
+// GetUpdatesContext is like GetUpdates but takes a context for cancellation and deadlines.
+func (bot *Bot) GetUpdatesContext(ctx context.Context, config UpdateConfig) ([]Update, error) {
 	v := url.Values{}
 	if config.Offset > 0 {
 		v.Add("offset", strconv.Itoa(config.Offset))
@@ -749,13 +902,15 @@ func (bot *Bot) GetUpdates(config UpdateConfig) ([]Update, error) {
 		v.Add("timeout", strconv.Itoa(config.Timeout))
 	}
 
-	resp, err := bot.MakeRequest("getUpdates", v)
+	resp, err := bot.MakeRequestContext(ctx, "getUpdates", v)
 	if err != nil {
 		return []Update{}, err
 	}
 
 	var updates []Update
-	json.Unmarshal(resp.Result, &updates)
+	if err := json.Unmarshal(resp.Result, &updates); err != nil {
+		return []Update{}, err
+	}
 
 	if bot.Debug {
 		log.Printf("getUpdates: %+v\n", updates)
@@ -766,13 +921,70 @@ func (bot *Bot) GetUpdates(config UpdateConfig) ([]Update, error) {
 
 // SetWebhook sets a webhook.
 // If this is set, GetUpdates will not get any data!
-func (bot *Bot) SetWebhook(v url.Values) error {
-	_, err := bot.MakeRequest("setWebhook", v)
-	return err
+//
+// Requires URL, unless Clear is set.
+// Certificate is a self-signed public key certificate to upload, given
+// as a path, FileBytes, or FileReader.
+// MaxConnections, AllowedUpdates, and SecretToken are optional.
+// The SecretToken is checked by WebhookHandler on every request.
+func (bot *Bot) SetWebhook(config WebhookConfig) error {
+	return bot.SetWebhookContext(context.Background(), config)
+}
+
+// SetWebhookContext is like SetWebhook but takes a context for cancellation and deadlines.
+func (bot *Bot) SetWebhookContext(ctx context.Context, config WebhookConfig) error {
+	if config.Clear {
+		return bot.ClearWebhookContext(ctx)
+	}
+
+	params := make(map[string]string)
+	if config.URL != nil {
+		params["url"] = config.URL.String()
+	}
+	if config.MaxConnections != 0 {
+		params["max_connections"] = strconv.Itoa(config.MaxConnections)
+	}
+	if config.AllowedUpdates != nil {
+		data, err := json.Marshal(config.AllowedUpdates)
+		if err != nil {
+			return err
+		}
+
+		params["allowed_updates"] = string(data)
+	}
+	if config.SecretToken != "" {
+		params["secret_token"] = config.SecretToken
+	}
+
+	var err error
+	if config.Certificate != nil {
+		_, err = bot.UploadFileContext(ctx, "setWebhook", params, "certificate", config.Certificate)
+	} else {
+		v := url.Values{}
+		for key, val := range params {
+			v.Add(key, val)
+		}
+
+		_, err = bot.MakeRequestContext(ctx, "setWebhook", v)
+	}
+	if err != nil {
+		return err
+	}
+
+	bot.updatesMu.Lock()
+	bot.webhookSecret = config.SecretToken
+	bot.updatesMu.Unlock()
+
+	return nil
 }
 
 // ClearWebhook removes a webhook
 func (bot *Bot) ClearWebhook() error {
-	_, err := bot.MakeRequest("setWebhook", url.Values{})
+	return bot.ClearWebhookContext(context.Background())
+}
+
+// ClearWebhookContext is like ClearWebhook but takes a context for cancellation and deadlines.
+func (bot *Bot) ClearWebhookContext(ctx context.Context) error {
+	_, err := bot.MakeRequestContext(ctx, "setWebhook", url.Values{})
 	return err
 }
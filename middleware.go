@@ -0,0 +1,55 @@
+package tgbotapi
+
+import (
+	"context"
+	"net/url"
+)
+
+// Request is a call to the Bot API, as seen by Middleware.
+type Request struct {
+	Endpoint string
+	Params   url.Values
+	// FileField and File are set when uploading a file,
+	// see UploadFile for the types File may have.
+	FileField string
+	File      interface{}
+
+	progress ProgressFunc
+}
+
+// RequestFunc makes a request to the Bot API.
+type RequestFunc func(ctx context.Context, req *Request) (APIResponse, error)
+
+// Middleware wraps a RequestFunc to run code around every request made by
+// a Bot, such as logging, metrics, or test assertions. It may change the
+// Request before calling next, inspect the response afterwards, or return
+// without calling next at all.
+type Middleware func(next RequestFunc) RequestFunc
+
+// Use adds middleware around every request the bot makes. The first
+// middleware added is the outermost one.
+//
+// Use is not safe to call while the bot is making requests.
+func (bot *Bot) Use(middleware ...Middleware) {
+	bot.middleware = append(bot.middleware, middleware...)
+}
+
+// call sends req through the middleware chain.
+func (bot *Bot) call(ctx context.Context, req *Request) (APIResponse, error) {
+	next := bot.transport
+	for i := len(bot.middleware) - 1; i >= 0; i-- {
+		next = bot.middleware[i](next)
+	}
+
+	return next(ctx, req)
+}
+
+// transport is the RequestFunc at the end of the middleware chain,
+// sending requests over HTTP.
+func (bot *Bot) transport(ctx context.Context, req *Request) (APIResponse, error) {
+	if req.File != nil {
+		return bot.postFile(ctx, req.Endpoint, req.Params, req.FileField, req.File, req.progress)
+	}
+
+	return bot.postForm(ctx, req.Endpoint, req.Params)
+}
@@ -0,0 +1,67 @@
+package tgbotapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var requests int
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	})
+
+	var calls []string
+	bot.Use(
+		func(next RequestFunc) RequestFunc {
+			return func(ctx context.Context, req *Request) (APIResponse, error) {
+				calls = append(calls, "outer "+req.Endpoint)
+				resp, err := next(ctx, req)
+				calls = append(calls, "outer done")
+				return resp, err
+			}
+		},
+		func(next RequestFunc) RequestFunc {
+			return func(ctx context.Context, req *Request) (APIResponse, error) {
+				calls = append(calls, "inner "+req.Params.Get("text")+" "+req.FileField)
+				if req.Params.Get("text") == "cached" {
+					result, _ := json.Marshal(Message{MessageID: 2})
+					return APIResponse{Ok: true, Result: result}, nil
+				}
+				return next(ctx, req)
+			}
+		},
+	)
+
+	if _, err := bot.Send(NewMessage(1, "hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	message, err := bot.Send(NewMessage(1, "cached"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.MessageID != 2 {
+		t.Errorf("expected the short-circuited message, got %+v", message)
+	}
+
+	if _, err := bot.Send(NewDocumentUpload(1, FileBytes{Name: "a.txt", Bytes: []byte("a")})); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"outer sendMessage", "inner hello ", "outer done",
+		"outer sendMessage", "inner cached ", "outer done",
+		"outer sendDocument", "inner  document", "outer done",
+	}
+	if strings.Join(calls, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected calls %q, got %q", expected, calls)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests to reach the server, got %d", requests)
+	}
+}
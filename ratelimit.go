@@ -0,0 +1,211 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Telegram's documented limits for outgoing messages.
+const (
+	DefaultGlobalRate = 30.0      // messages per second, across all chats
+	DefaultChatRate   = 1.0       // messages per second, in one private chat
+	DefaultGroupRate  = 20.0 / 60 // messages per second, in one group
+)
+
+// limitedChatID returns the chat a request counts against in the bot's
+// RateLimiter, or "" if the request doesn't send a message. Telegram's
+// flood limits are on messages, so chat actions, edits, deletions and
+// reads are not paced.
+func limitedChatID(endpoint string, params url.Values) string {
+	if endpoint == "sendChatAction" || !(strings.HasPrefix(endpoint, "send") || endpoint == "forwardMessage") {
+		return ""
+	}
+
+	return params.Get("chat_id")
+}
+
+// RateLimitConfig contains the limits enforced by a RateLimiter.
+// Rates are in requests per second; zero values use the defaults above.
+type RateLimitConfig struct {
+	Global float64
+	Chat   float64
+	Group  float64
+	// FailFast makes requests that would have to wait fail immediately
+	// with a *RateLimitError instead of blocking until a slot frees.
+	FailFast bool
+}
+
+// RateLimitError is returned instead of waiting when the limiter
+// is set to fail fast.
+type RateLimitError struct {
+	ChatID  int
+	RetryIn time.Duration
+}
+
+// Error describes which limit was hit.
+func (e *RateLimitError) Error() string {
+	if e.ChatID == 0 {
+		return fmt.Sprintf("rate limit exceeded, retry in %v", e.RetryIn)
+	}
+
+	return fmt.Sprintf("rate limit exceeded for chat %d, retry in %v", e.ChatID, e.RetryIn)
+}
+
+// IsRateLimited reports whether err is a *RateLimitError.
+func IsRateLimited(err error) bool {
+	var rateErr *RateLimitError
+	return errors.As(err, &rateErr)
+}
+
+// RateLimiter paces outgoing messages so they stay within Telegram's
+// global, per chat and per group limits.
+//
+// Group chats are recognised by their negative IDs.
+type RateLimiter struct {
+	config RateLimitConfig
+
+	mu        sync.Mutex
+	global    *bucket
+	chats     map[int]*bucket
+	lastPrune time.Time
+}
+
+// NewRateLimiter creates a RateLimiter with the given limits.
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	if config.Global <= 0 {
+		config.Global = DefaultGlobalRate
+	}
+	if config.Chat <= 0 {
+		config.Chat = DefaultChatRate
+	}
+	if config.Group <= 0 {
+		config.Group = DefaultGroupRate
+	}
+
+	return &RateLimiter{
+		config: config,
+		global: newBucket(config.Global, time.Now()),
+		chats:  make(map[int]*bucket),
+	}
+}
+
+// Wait blocks until a request to chatID may be sent, or returns a
+// *RateLimitError if the limiter fails fast. A chatID of 0 is only
+// subject to the global limit.
+func (l *RateLimiter) Wait(ctx context.Context, chatID int) error {
+	wait, err := l.reserve(chatID)
+	if err != nil {
+		return err
+	}
+
+	if err := sleepContext(ctx, wait); err != nil {
+		l.cancel(chatID)
+		return err
+	}
+
+	return nil
+}
+
+// reserve takes a slot for chatID and returns how long to wait before using it.
+func (l *RateLimiter) reserve(chatID int) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.prune(now)
+
+	buckets := []*bucket{l.global}
+	if chatID != 0 {
+		b, ok := l.chats[chatID]
+		if !ok {
+			rate := l.config.Chat
+			if chatID < 0 {
+				rate = l.config.Group
+			}
+
+			b = newBucket(rate, now)
+			l.chats[chatID] = b
+		}
+
+		buckets = append(buckets, b)
+	}
+
+	var wait time.Duration
+	for _, b := range buckets {
+		if w := b.wait(now); w > wait {
+			wait = w
+		}
+	}
+
+	if wait > 0 && l.config.FailFast {
+		return 0, &RateLimitError{ChatID: chatID, RetryIn: wait}
+	}
+
+	for _, b := range buckets {
+		b.tokens--
+	}
+
+	return wait, nil
+}
+
+// cancel returns a slot taken by reserve that was never used.
+func (l *RateLimiter) cancel(chatID int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.global.tokens++
+	if b, ok := l.chats[chatID]; ok {
+		b.tokens++
+	}
+}
+
+// prune forgets chats whose buckets have refilled, at most once a minute.
+func (l *RateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Minute {
+		return
+	}
+	l.lastPrune = now
+
+	for id, b := range l.chats {
+		if b.wait(now) == 0 && b.tokens >= b.burst {
+			delete(l.chats, id)
+		}
+	}
+}
+
+// bucket is a token bucket holding up to one second's worth of requests.
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate float64, now time.Time) *bucket {
+	burst := math.Max(1, math.Floor(rate))
+
+	return &bucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// wait refills the bucket and returns how long until a token is available.
+func (b *bucket) wait(now time.Time) time.Duration {
+	b.tokens = math.Min(b.burst, b.tokens+b.rate*now.Sub(b.last).Seconds())
+	b.last = now
+
+	if b.tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
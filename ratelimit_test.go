@@ -0,0 +1,94 @@
+package tgbotapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterChat(t *testing.T) {
+	l := NewRateLimiter(RateLimitConfig{Chat: 20})
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 23; i++ {
+		if err := l.Wait(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A second's worth of requests is sent at once, the following three
+	// wait 50ms each.
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("requests were not paced, took %v", elapsed)
+	}
+
+	// Other chats have their own limit.
+	start = time.Now()
+	if err := l.Wait(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("another chat had to wait %v", elapsed)
+	}
+}
+
+func TestRateLimiterFailFast(t *testing.T) {
+	l := NewRateLimiter(RateLimitConfig{FailFast: true})
+	ctx := context.Background()
+
+	if err := l.Wait(ctx, -100); err != nil {
+		t.Fatal(err)
+	}
+
+	err := l.Wait(ctx, -100)
+	if !IsRateLimited(err) {
+		t.Fatalf("expected a *RateLimitError, got %v", err)
+	}
+	if retryIn := err.(*RateLimitError).RetryIn; retryIn < 2*time.Second {
+		t.Errorf("groups should wait about 3s, got %v", retryIn)
+	}
+}
+
+func TestRateLimiterBot(t *testing.T) {
+	var calls int
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	})
+	bot.limiter = NewRateLimiter(RateLimitConfig{FailFast: true})
+
+	if _, err := bot.SendMessage(NewMessage(1, "hello")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.SendLocation(NewLocation(1, 0, 0)); !IsRateLimited(err) {
+		t.Errorf("expected a *RateLimitError, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 request to reach the server, got %d", calls)
+	}
+}
+
+func TestRateLimiterOnlyMessages(t *testing.T) {
+	var calls int
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	})
+	bot.limiter = NewRateLimiter(RateLimitConfig{FailFast: true})
+
+	// "typing..." and then the reply must not wait for each other.
+	if err := bot.SendChatAction(NewChatAction(1, ChatTyping)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.SendMessage(NewMessage(1, "hello")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := bot.EditMessageText(NewEditMessageText(1, 1, "edited")); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 requests to reach the server, got %d", calls)
+	}
+}
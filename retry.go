@@ -0,0 +1,121 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how requests that failed with a temporary error
+// are retried. A nil *RetryPolicy never retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry.
+	// It doubles with every following attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts, unless Telegram asks
+	// for a longer wait with retry_after.
+	MaxDelay time.Duration
+	// Jitter randomly shortens each delay by up to this fraction (0 to 1),
+	// so many clients don't retry at the same moment. Values past 1 are
+	// treated as 1.
+	Jitter float64
+	// Retryable decides whether an error is worth retrying.
+	// Defaults to IsRetryable.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy returns a RetryPolicy making up to three attempts,
+// suitable for most bots.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Second,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// IsRetryable reports whether err is a temporary failure: a 429 flood wait,
+// a 5xx server error or a network error.
+func IsRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// shouldRetry reports whether another attempt should follow attempt,
+// which failed with err.
+func (p *RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+
+	return IsRetryable(err)
+}
+
+// delay returns how long to wait after attempt failed with err.
+func (p *RetryPolicy) delay(attempt int, err error) time.Duration {
+	// Without a MaxDelay, doubling stops before the delay overflows.
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay) && d <= math.MaxInt64/2; i++ {
+		d *= 2
+	}
+
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	jitter := p.Jitter
+	if jitter > 1 {
+		jitter = 1
+	}
+	if jitter > 0 {
+		d -= time.Duration(jitter * rand.Float64() * float64(d))
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		if wait := time.Duration(apiErr.RetryAfter) * time.Second; wait > d {
+			d = wait
+		}
+	}
+
+	return d
+}
+
+// sleepContext waits for d, returning early with ctx.Err()
+// if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,109 @@
+package tgbotapi
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryServerErrors(t *testing.T) {
+	var calls int32
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	})
+	bot.retry = &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	if _, err := bot.SendMessage(NewMessage(1, "hello")); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	var calls int32
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`))
+	})
+	bot.retry = &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	if _, err := bot.SendMessage(NewMessage(1, "hello")); !IsForbidden(err) {
+		t.Errorf("expected a 403 error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("403 should not be retried, got %d attempts", calls)
+	}
+}
+
+func TestRetryUpload(t *testing.T) {
+	var calls int32
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Error(err)
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	})
+	bot.retry = &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+
+	if _, err := bot.UploadFile("sendDocument", map[string]string{"chat_id": "1"}, "document", "README.md"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	p := &RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	tests := []struct {
+		attempt int
+		err     error
+		want    time.Duration
+	}{
+		{1, errors.New("network"), time.Second},
+		{2, errors.New("network"), 2 * time.Second},
+		{4, errors.New("network"), 5 * time.Second},
+		{1, &APIError{Code: 429, ResponseParameters: ResponseParameters{RetryAfter: 12}}, 12 * time.Second},
+	}
+
+	for _, test := range tests {
+		if got := p.delay(test.attempt, test.err); got != test.want {
+			t.Errorf("delay(%d, %v) = %v, want %v", test.attempt, test.err, got, test.want)
+		}
+	}
+}
+
+func TestRetryDelayBounds(t *testing.T) {
+	// Without a MaxDelay, the delay keeps growing without overflowing.
+	p := &RetryPolicy{BaseDelay: time.Second}
+	last := time.Duration(0)
+	for attempt := 1; attempt <= 100; attempt++ {
+		d := p.delay(attempt, errors.New("network"))
+		if d < last {
+			t.Fatalf("delay(%d) = %v, shorter than the delay before it, %v", attempt, d, last)
+		}
+		last = d
+	}
+
+	p = &RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute, Jitter: 5}
+	for attempt := 1; attempt <= 10; attempt++ {
+		if d := p.delay(attempt, errors.New("network")); d < 0 {
+			t.Errorf("delay(%d) = %v with a Jitter past 1", attempt, d)
+		}
+	}
+}
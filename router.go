@@ -0,0 +1,187 @@
+package tgbotapi
+
+import (
+	"log"
+	"regexp"
+	"runtime/debug"
+	"strings"
+)
+
+// Kinds of message, as returned by MessageKind and used by Router.HandleKind.
+const (
+	KindText                = "text"
+	KindPhoto               = "photo"
+	KindAudio               = "audio"
+	KindDocument            = "document"
+	KindSticker             = "sticker"
+	KindVideo               = "video"
+	KindContact             = "contact"
+	KindLocation            = "location"
+	KindNewChatParticipant  = "new_chat_participant"
+	KindLeftChatParticipant = "left_chat_participant"
+	KindNewChatTitle        = "new_chat_title"
+	KindNewChatPhoto        = "new_chat_photo"
+	KindDeleteChatPhoto     = "delete_chat_photo"
+	KindGroupChatCreated    = "group_chat_created"
+)
+
+// MessageKind returns what a message contains, one of the Kind constants,
+// or "" if it is empty or of a kind not known.
+func MessageKind(m Message) string {
+	switch {
+	case m.Text != "":
+		return KindText
+	case len(m.Photo) > 0:
+		return KindPhoto
+	case m.Audio.FileID != "":
+		return KindAudio
+	case m.Document.FileID != "":
+		return KindDocument
+	case m.Sticker.FileID != "":
+		return KindSticker
+	case m.Video.FileID != "":
+		return KindVideo
+	case m.Contact.PhoneNumber != "":
+		return KindContact
+	case m.Location.Latitude != 0 || m.Location.Longitude != 0:
+		return KindLocation
+	case m.NewChatParticipant.ID != 0:
+		return KindNewChatParticipant
+	case m.LeftChatParticipant.ID != 0:
+		return KindLeftChatParticipant
+	case m.NewChatTitle != "":
+		return KindNewChatTitle
+	case m.NewChatPhoto != "":
+		return KindNewChatPhoto
+	case m.DeleteChatPhoto:
+		return KindDeleteChatPhoto
+	case m.GroupChatCreated:
+		return KindGroupChatCreated
+	}
+
+	return ""
+}
+
+// HandlerFunc handles an update routed to it by a Router.
+type HandlerFunc func(bot *Bot, update Update)
+
+// Router dispatches updates to handlers registered by command, by a
+// regexp matching the message text, or by the kind of message.
+// Updates with a CallbackQuery go to the callback query handler.
+//
+// An update goes to the first handler that matches, checking commands
+// first, then regexps in the order they were added, then kinds. Commands
+// addressed to another bot with an @botname are not routed as commands.
+// Updates nothing matches go to the fallback handler, if one is set.
+//
+// Handlers must be registered before calling Serve.
+type Router struct {
+	// PanicHandler is called when a handler panics, with the update being
+	// handled and the recovered value. By default the panic is logged.
+	PanicHandler func(update Update, v interface{})
+
+	bot      *Bot
+	commands map[string]HandlerFunc
+	patterns []patternHandler
+	kinds    map[string]HandlerFunc
+	callback HandlerFunc
+	fallback HandlerFunc
+}
+
+type patternHandler struct {
+	re      *regexp.Regexp
+	handler HandlerFunc
+}
+
+// NewRouter creates a Router passing bot to its handlers.
+func NewRouter(bot *Bot) *Router {
+	return &Router{
+		bot:      bot,
+		commands: make(map[string]HandlerFunc),
+		kinds:    make(map[string]HandlerFunc),
+	}
+}
+
+// HandleCommand routes messages starting with command, such as "/start",
+// to handler. The leading slash may be left out.
+func (r *Router) HandleCommand(command string, handler HandlerFunc) {
+	r.commands[strings.TrimPrefix(command, "/")] = handler
+}
+
+// HandleRegexp routes messages with text matching re to handler.
+func (r *Router) HandleRegexp(re *regexp.Regexp, handler HandlerFunc) {
+	r.patterns = append(r.patterns, patternHandler{re, handler})
+}
+
+// HandleKind routes messages of kind, one of the Kind constants, to handler.
+func (r *Router) HandleKind(kind string, handler HandlerFunc) {
+	r.kinds[kind] = handler
+}
+
+// HandleCallbackQuery routes updates with a CallbackQuery to handler.
+func (r *Router) HandleCallbackQuery(handler HandlerFunc) {
+	r.callback = handler
+}
+
+// HandleFallback routes updates no other handler matches to handler.
+func (r *Router) HandleFallback(handler HandlerFunc) {
+	r.fallback = handler
+}
+
+// Serve routes updates until the chan is closed, such as by
+// StopReceivingUpdates. Updates are handled one at a time, in order.
+func (r *Router) Serve(updates <-chan Update) {
+	for update := range updates {
+		r.HandleUpdate(update)
+	}
+}
+
+// HandleUpdate routes a single update. A panic while routing or in the
+// handler is recovered and passed to PanicHandler.
+func (r *Router) HandleUpdate(update Update) {
+	defer func() {
+		if v := recover(); v != nil {
+			if r.PanicHandler != nil {
+				r.PanicHandler(update, v)
+				return
+			}
+
+			log.Printf("panic handling update %d: %v\n%s", update.UpdateID, v, debug.Stack())
+		}
+	}()
+
+	if handler := r.match(update); handler != nil {
+		handler(r.bot, update)
+	}
+}
+
+// match returns the handler for an update, or nil if there is none.
+func (r *Router) match(update Update) HandlerFunc {
+	if update.CallbackQuery != nil {
+		if r.callback != nil {
+			return r.callback
+		}
+		return r.fallback
+	}
+
+	m := update.Message
+	if command := r.bot.Command(m); command != "" {
+		if handler, ok := r.commands[command]; ok {
+			return handler
+		}
+	}
+
+	if m.Text != "" {
+		for _, p := range r.patterns {
+			if p.re.MatchString(m.Text) {
+				return p.handler
+			}
+		}
+	}
+
+	if handler, ok := r.kinds[MessageKind(m)]; ok {
+		return handler
+	}
+
+	return r.fallback
+}
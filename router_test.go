@@ -0,0 +1,119 @@
+package tgbotapi
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	var routed []string
+	handle := func(name string) HandlerFunc {
+		return func(bot *Bot, update Update) {
+			routed = append(routed, name)
+		}
+	}
+
+	router := NewRouter(&Bot{self: &User{UserName: "test_bot"}})
+	router.HandleCommand("/start", handle("start"))
+	router.HandleCommand("help", handle("help"))
+	router.HandleRegexp(regexp.MustCompile(`^hello`), handle("hello"))
+	router.HandleRegexp(regexp.MustCompile(`hello`), handle("hello again"))
+	router.HandleKind(KindPhoto, handle("photo"))
+	router.HandleKind(KindNewChatTitle, handle("title"))
+	router.HandleFallback(handle("fallback"))
+
+	updates := make(chan Update, 10)
+	for _, m := range []Message{
+		{Text: "/start"},
+		{Text: "/help@test_bot me"},
+		{Text: "/help@other_bot"},
+		{Text: "/unknown"},
+		{Text: "hello there"},
+		{Text: "well hello"},
+		{Photo: []PhotoSize{{FileID: "a"}}},
+		{NewChatTitle: "New title"},
+		{Location: Location{Latitude: 1}},
+		{},
+	} {
+		updates <- Update{Message: m}
+	}
+	close(updates)
+
+	router.Serve(updates)
+
+	expected := []string{"start", "help", "fallback", "fallback", "hello", "hello again", "photo", "title", "fallback", "fallback"}
+	if len(routed) != len(expected) {
+		t.Fatalf("expected %q, got %q", expected, routed)
+	}
+	for i := range expected {
+		if routed[i] != expected[i] {
+			t.Errorf("update %d: expected %q, got %q", i, expected[i], routed[i])
+		}
+	}
+}
+
+func TestRouterRecoversPanics(t *testing.T) {
+	router := NewRouter(&Bot{self: &User{UserName: "test_bot"}})
+	router.HandleCommand("panic", func(bot *Bot, update Update) {
+		panic("oops")
+	})
+
+	var handled int
+	router.HandleFallback(func(bot *Bot, update Update) {
+		handled++
+	})
+
+	var recovered interface{}
+	router.PanicHandler = func(update Update, v interface{}) {
+		recovered = v
+	}
+
+	router.HandleUpdate(Update{Message: Message{Text: "/panic"}})
+	router.HandleUpdate(Update{Message: Message{Text: "after"}})
+
+	if recovered != "oops" {
+		t.Errorf("expected the panic to be recovered, got %v", recovered)
+	}
+	if handled != 1 {
+		t.Errorf("expected later updates to be handled, got %d", handled)
+	}
+}
+
+func TestMessageKind(t *testing.T) {
+	tests := []struct {
+		message Message
+		kind    string
+	}{
+		{Message{Text: "hi"}, KindText},
+		{Message{Document: Document{FileID: "a"}}, KindDocument},
+		{Message{Contact: Contact{PhoneNumber: "1"}}, KindContact},
+		{Message{NewChatParticipant: User{ID: 1}}, KindNewChatParticipant},
+		{Message{GroupChatCreated: true}, KindGroupChatCreated},
+		{Message{}, ""},
+	}
+
+	for _, test := range tests {
+		if kind := MessageKind(test.message); kind != test.kind {
+			t.Errorf("expected %q for %+v, got %q", test.kind, test.message, kind)
+		}
+	}
+}
+
+func TestRouterWithoutSelf(t *testing.T) {
+	router := NewRouter(&Bot{})
+
+	var routed []string
+	router.HandleCommand("start", func(bot *Bot, update Update) {
+		routed = append(routed, update.Message.Text)
+	})
+	router.HandleFallback(func(bot *Bot, update Update) {
+		routed = append(routed, "fallback")
+	})
+
+	router.HandleUpdate(Update{Message: Message{Text: "/start"}})
+	router.HandleUpdate(Update{Message: Message{Text: "/start@x"}})
+
+	if len(routed) != 2 || routed[0] != "/start" || routed[1] != "fallback" {
+		t.Errorf("expected /start to be routed and /start@x to fall back, got %q", routed)
+	}
+}
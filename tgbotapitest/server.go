@@ -0,0 +1,380 @@
+// Package tgbotapitest provides a fake Telegram Bot API server, so bots
+// built with tgbotapi can be tested without a token or network access.
+package tgbotapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/pho/telegram-bot-api"
+)
+
+// Token is the token the fake server accepts.
+const Token = "123456:TEST"
+
+// SentMessage is a request the bot sent to the server.
+type SentMessage struct {
+	Method string
+	Params url.Values
+	// FileField, FileName and FileData are set when a file was uploaded.
+	FileField string
+	FileName  string
+	FileData  []byte
+	// Message is the result returned to the bot.
+	Message tgbotapi.Message
+}
+
+// Failure describes how the server should fail a request.
+type Failure struct {
+	Code        int
+	Description string
+	RetryAfter  int
+	// Delay holds the response back, to simulate a slow or hung server.
+	// The request is abandoned early if the client gives up.
+	Delay time.Duration
+}
+
+// Server is a fake Telegram Bot API server.
+type Server struct {
+	*httptest.Server
+
+	// Self is returned by getMe.
+	Self tgbotapi.User
+
+	mu            sync.Mutex
+	updates       []tgbotapi.Update
+	nextUpdateID  int
+	nextMessageID int
+	newUpdates    chan struct{}
+	sent          []SentMessage
+	failures      map[string][]Failure
+	webhook       url.Values
+}
+
+// NewServer starts a fake server. Call Close when done with it.
+func NewServer() *Server {
+	s := &Server{
+		Self: tgbotapi.User{
+			ID:        123456,
+			FirstName: "Test",
+			UserName:  "test_bot",
+		},
+		nextUpdateID:  1,
+		nextMessageID: 1,
+		newUpdates:    make(chan struct{}),
+		failures:      make(map[string][]Failure),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// NewBot creates a tgbotapi.Bot talking to the server.
+func (s *Server) NewBot() (*tgbotapi.Bot, error) {
+	return s.NewBotWithOptions(tgbotapi.BotOptions{})
+}
+
+// NewBotWithOptions is like NewBot, but takes options for the bot.
+// The Endpoint and Client are always set to the server's.
+func (s *Server) NewBotWithOptions(options tgbotapi.BotOptions) (*tgbotapi.Bot, error) {
+	options.Endpoint = s.URL
+	options.Client = s.Client()
+
+	return tgbotapi.NewBotWithOptions(Token, options)
+}
+
+// AddUpdate queues an update for getUpdates.
+// An UpdateID is assigned if it has none.
+func (s *Server) AddUpdate(update tgbotapi.Update) tgbotapi.Update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if update.UpdateID == 0 {
+		update.UpdateID = s.nextUpdateID
+	}
+	if update.UpdateID >= s.nextUpdateID {
+		s.nextUpdateID = update.UpdateID + 1
+	}
+
+	s.updates = append(s.updates, update)
+
+	// Wake up any long polls.
+	close(s.newUpdates)
+	s.newUpdates = make(chan struct{})
+
+	return update
+}
+
+// AddMessage queues an update with a text message from a user in a private chat.
+func (s *Server) AddMessage(chatID int, text string) tgbotapi.Update {
+	s.mu.Lock()
+	id := s.nextMessageID
+	s.nextMessageID++
+	s.mu.Unlock()
+
+	return s.AddUpdate(tgbotapi.Update{
+		Message: tgbotapi.Message{
+			MessageID: id,
+			From:      tgbotapi.User{ID: chatID, FirstName: "User"},
+			Date:      int(time.Now().Unix()),
+			Chat:      tgbotapi.UserOrGroupChat{ID: chatID, FirstName: "User"},
+			Text:      text,
+		},
+	})
+}
+
+// AddCallbackQuery queues an update for the user pressing a button with
+// data on message, which should be one the bot sent.
+func (s *Server) AddCallbackQuery(message tgbotapi.Message, data string) tgbotapi.Update {
+	s.mu.Lock()
+	id := s.nextUpdateID
+	s.mu.Unlock()
+
+	return s.AddUpdate(tgbotapi.Update{
+		CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:      strconv.Itoa(id),
+			From:    tgbotapi.User{ID: message.Chat.ID, FirstName: "User"},
+			Message: &message,
+			Data:    data,
+		},
+	})
+}
+
+// Sent returns the requests the bot has sent, oldest first.
+// Reads such as getMe and getUpdates are not included.
+func (s *Server) Sent() []SentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]SentMessage(nil), s.sent...)
+}
+
+// Webhook returns the parameters of the last setWebhook request,
+// or nil if none was made.
+func (s *Server) Webhook() url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.webhook
+}
+
+// Fail makes the next request to method fail with an API error.
+func (s *Server) Fail(method string, code int, description string) {
+	s.FailWith(method, Failure{Code: code, Description: description})
+}
+
+// FailWith makes the next request to method fail as described.
+// Failures for a method are used in the order they were added.
+func (s *Server) FailWith(method string, failure Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[method] = append(s.failures[method], failure)
+}
+
+func (s *Server) nextFailure(method string) (Failure, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failures := s.failures[method]
+	if len(failures) == 0 {
+		return Failure{}, false
+	}
+
+	s.failures[method] = failures[1:]
+	return failures[0], true
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/bot"), "/", 2)
+	if len(parts) != 2 || parts[0] != Token {
+		writeError(w, Failure{Code: http.StatusUnauthorized, Description: "Unauthorized"})
+		return
+	}
+	method := parts[1]
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil && err != http.ErrNotMultipart {
+		writeError(w, Failure{Code: http.StatusBadRequest, Description: "Bad Request: " + err.Error()})
+		return
+	}
+
+	if failure, ok := s.nextFailure(method); ok {
+		if failure.Delay > 0 {
+			select {
+			case <-time.After(failure.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if failure.Code != 0 {
+			writeError(w, failure)
+			return
+		}
+	}
+
+	switch method {
+	case "getMe":
+		writeResult(w, s.Self)
+	case "getUpdates":
+		s.getUpdates(w, r)
+	case "setWebhook":
+		s.mu.Lock()
+		s.webhook = r.Form
+		s.mu.Unlock()
+
+		writeResult(w, true)
+	case "sendChatAction", "answerCallbackQuery", "deleteMessage":
+		s.record(r, method, "", tgbotapi.Message{})
+		writeResult(w, true)
+	case "sendMessage", "forwardMessage", "sendLocation":
+		writeResult(w, s.record(r, method, "", s.newMessage(r)))
+	case "editMessageText", "editMessageCaption", "editMessageReplyMarkup":
+		message := s.record(r, method, "", editedMessage(r))
+
+		// Messages sent inline aren't returned.
+		if r.FormValue("inline_message_id") != "" {
+			writeResult(w, true)
+			return
+		}
+		writeResult(w, message)
+	case "sendPhoto", "sendAudio", "sendDocument", "sendSticker", "sendVideo":
+		field := strings.ToLower(strings.TrimPrefix(method, "send"))
+		writeResult(w, s.record(r, method, field, s.newMessage(r)))
+	default:
+		writeError(w, Failure{Code: http.StatusNotFound, Description: "Not Found: method not found"})
+	}
+}
+
+// getUpdates confirms updates before offset and returns the rest,
+// waiting up to timeout seconds for one to arrive.
+func (s *Server) getUpdates(w http.ResponseWriter, r *http.Request) {
+	offset, _ := strconv.Atoi(r.FormValue("offset"))
+	limit, _ := strconv.Atoi(r.FormValue("limit"))
+	timeout, _ := strconv.Atoi(r.FormValue("timeout"))
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	deadline := time.After(time.Duration(timeout) * time.Second)
+	for {
+		s.mu.Lock()
+		pending := s.updates[:0]
+		for _, update := range s.updates {
+			if update.UpdateID >= offset {
+				pending = append(pending, update)
+			}
+		}
+		s.updates = pending
+		if len(pending) > limit {
+			pending = pending[:limit]
+		}
+		result := append([]tgbotapi.Update{}, pending...)
+		wait := s.newUpdates
+		s.mu.Unlock()
+
+		if len(result) > 0 || timeout <= 0 {
+			writeResult(w, result)
+			return
+		}
+
+		select {
+		case <-wait:
+		case <-deadline:
+			timeout = 0
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// newMessage creates the message returned for a send request.
+func (s *Server) newMessage(r *http.Request) tgbotapi.Message {
+	s.mu.Lock()
+	id := s.nextMessageID
+	s.nextMessageID++
+	s.mu.Unlock()
+
+	chatID, _ := strconv.Atoi(r.FormValue("chat_id"))
+
+	return tgbotapi.Message{
+		MessageID: id,
+		From:      s.Self,
+		Date:      int(time.Now().Unix()),
+		Chat:      tgbotapi.UserOrGroupChat{ID: chatID},
+		Text:      r.FormValue("text"),
+	}
+}
+
+// editedMessage creates the message returned for an edit request.
+// The server doesn't keep the messages it sent, so only the fields in
+// the request are set.
+func editedMessage(r *http.Request) tgbotapi.Message {
+	chatID, _ := strconv.Atoi(r.FormValue("chat_id"))
+	messageID, _ := strconv.Atoi(r.FormValue("message_id"))
+
+	return tgbotapi.Message{
+		MessageID: messageID,
+		Chat:      tgbotapi.UserOrGroupChat{ID: chatID},
+		Text:      r.FormValue("text"),
+	}
+}
+
+// record stores a request sent by the bot, returning message.
+func (s *Server) record(r *http.Request, method string, field string, message tgbotapi.Message) tgbotapi.Message {
+	sent := SentMessage{
+		Method:  method,
+		Params:  r.Form,
+		Message: message,
+	}
+
+	if field != "" {
+		if f, header, err := r.FormFile(field); err == nil {
+			sent.FileField = field
+			sent.FileName = header.Filename
+			sent.FileData, _ = ioutil.ReadAll(f)
+			f.Close()
+		}
+	}
+
+	s.mu.Lock()
+	s.sent = append(s.sent, sent)
+	s.mu.Unlock()
+
+	return message
+}
+
+func writeResult(w http.ResponseWriter, result interface{}) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		writeError(w, Failure{Code: http.StatusInternalServerError, Description: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: data})
+}
+
+func writeError(w http.ResponseWriter, failure Failure) {
+	resp := tgbotapi.APIResponse{
+		ErrorCode:   failure.Code,
+		Description: failure.Description,
+	}
+	if resp.Description == "" {
+		resp.Description = fmt.Sprintf("%d %s", failure.Code, http.StatusText(failure.Code))
+	}
+	if failure.RetryAfter > 0 {
+		resp.Parameters = &tgbotapi.ResponseParameters{RetryAfter: failure.RetryAfter}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(failure.Code)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,76 @@
+package tgbotapitest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/pho/telegram-bot-api"
+)
+
+func TestServerFailures(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBotWithOptions(tgbotapi.BotOptions{
+		Retry: &tgbotapi.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A 429 is retried, honouring retry_after.
+	server.FailWith("sendMessage", Failure{Code: http.StatusTooManyRequests, RetryAfter: 1})
+	start := time.Now()
+	if _, err := bot.Send(tgbotapi.NewMessage(1, "hello")); err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) < time.Second {
+		t.Error("retry_after was not honoured")
+	}
+
+	// A 403 is not.
+	server.Fail("sendMessage", http.StatusForbidden, "Forbidden: bot was blocked by the user")
+	if _, err := bot.Send(tgbotapi.NewMessage(1, "hello")); !tgbotapi.IsForbidden(err) {
+		t.Errorf("expected a 403 error, got %v", err)
+	}
+
+	// A hung request times out.
+	server.FailWith("sendMessage", Failure{Delay: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := bot.SendContext(ctx, tgbotapi.NewMessage(1, "hello")); err != context.DeadlineExceeded {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+
+	if sent := server.Sent(); len(sent) != 1 {
+		t.Errorf("expected 1 message sent, got %d", len(sent))
+	}
+}
+
+func TestServerUploads(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	bot, err := server.NewBot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := tgbotapi.FileBytes{Name: "photo.jpg", Bytes: []byte("jpeg")}
+	if _, err := bot.Send(tgbotapi.NewPhotoUpload(7, file)); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := server.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 message sent, got %d", len(sent))
+	}
+	if sent[0].Method != "sendPhoto" || sent[0].FileName != "photo.jpg" || string(sent[0].FileData) != "jpeg" {
+		t.Errorf("unexpected upload %+v", sent[0])
+	}
+	if sent[0].Message.Chat.ID != 7 {
+		t.Errorf("expected the photo in chat 7, got %d", sent[0].Message.Chat.ID)
+	}
+}
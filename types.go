@@ -1,27 +1,76 @@
 package tgbotapi
 
-import "encoding/json"
-
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// Bot allows you to interact with the Telegram Bot API.
 type Bot struct {
 	Debug bool
 
-	token   string
-	self    *User
-	updates chan Update
+	token       string
+	endpoint    string
+	client      *http.Client
+	retry       *RetryPolicy
+	limiter     *RateLimiter
+	maxDownload int64
+	middleware  []Middleware
+	self        *User
+
+	updatesMu     sync.Mutex
+	stopUpdates   context.CancelFunc
+	updatesDone   chan struct{}
+	updatesOffset int
+	updatesErr    error
+	webhookSecret string
+}
+
+// BotOptions contains optional settings for a Bot, used by NewBotWithOptions.
+type BotOptions struct {
+	// Endpoint is the base URL of the Bot API server, without the
+	// trailing /bot<token>. Defaults to APIEndpoint.
+	Endpoint string
+	// Client is used for every request. Defaults to http.DefaultClient.
+	Client *http.Client
+	// Retry sets how failed requests are retried. Requests are not
+	// retried if it is nil.
+	Retry *RetryPolicy
+	// RateLimiter paces messages sent to chats. Messages are not
+	// limited if it is nil.
+	RateLimiter *RateLimiter
+	// WebhookSecretToken is checked by WebhookHandler when the webhook
+	// was set up by another process. SetWebhook replaces it.
+	WebhookSecretToken string
+	// MaxDownloadSize is the largest file DownloadFile accepts.
+	// Defaults to DefaultMaxDownloadSize.
+	MaxDownloadSize int64
+	// Middleware wraps every request, the first one outermost.
+	Middleware []Middleware
 }
 
 // APIResponse is a response from the Telegram API with the result stored raw.
 type APIResponse struct {
-	Ok          bool            `json:"ok"`
-	Result      json.RawMessage `json:"result"`
-	ErrorCode   int             `json:"error_code"`
-	Description string          `json:"description"`
+	Ok          bool                `json:"ok"`
+	Result      json.RawMessage     `json:"result"`
+	ErrorCode   int                 `json:"error_code"`
+	Description string              `json:"description"`
+	Parameters  *ResponseParameters `json:"parameters"`
+}
+
+// ResponseParameters contains information about why a request was unsuccessful.
+type ResponseParameters struct {
+	MigrateToChatID int `json:"migrate_to_chat_id"`
+	RetryAfter      int `json:"retry_after"`
 }
 
 // Update is an update response, from GetUpdates.
 type Update struct {
-	UpdateID int     `json:"update_id"`
-	Message  Message `json:"message"`
+	UpdateID      int            `json:"update_id"`
+	Message       Message        `json:"message"`
+	CallbackQuery *CallbackQuery `json:"callback_query"`
 }
 
 // User is a user, contained in Message and returned by GetSelf.
@@ -138,6 +187,13 @@ type UserProfilePhotos struct {
 	Photos     []PhotoSize `json:"photos"`
 }
 
+// File contains information about a file to download from Telegram.
+type File struct {
+	FileID   string `json:"file_id"`
+	FileSize int    `json:"file_size"`
+	FilePath string `json:"file_path"`
+}
+
 // ReplyKeyboardMarkup allows the Bot to set a custom keyboard.
 type ReplyKeyboardMarkup struct {
 	Keyboard        [][]string `json:"keyboard"`
@@ -155,5 +211,33 @@ type ReplyKeyboardHide struct {
 // ForceReply allows the Bot to have users directly reply to it without additional interaction.
 type ForceReply struct {
 	ForceReply bool `json:"force_reply"`
-	Selective  bool `json:"force_reply"`
+	Selective  bool `json:"selective"`
+}
+
+// InlineKeyboardMarkup is a keyboard of buttons shown under a message.
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// InlineKeyboardButton is a button on an InlineKeyboardMarkup.
+// Exactly one of URL, CallbackData or SwitchInlineQuery must be set.
+//
+// SwitchInlineQuery is a pointer because an empty query is allowed,
+// and only inserts the bot's username.
+type InlineKeyboardButton struct {
+	Text              string  `json:"text"`
+	URL               string  `json:"url,omitempty"`
+	CallbackData      string  `json:"callback_data,omitempty"`
+	SwitchInlineQuery *string `json:"switch_inline_query,omitempty"`
+}
+
+// CallbackQuery is sent when a user presses a button with CallbackData.
+// Message is nil if the button was on a message sent inline, which
+// InlineMessageID identifies instead.
+type CallbackQuery struct {
+	ID              string   `json:"id"`
+	From            User     `json:"from"`
+	Message         *Message `json:"message"`
+	InlineMessageID string   `json:"inline_message_id"`
+	Data            string   `json:"data"`
 }
@@ -1,56 +1,230 @@
 package tgbotapi
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
-	"net/url"
-	"strconv"
+	"mime"
+	"net/http"
+	"time"
 )
 
-// GetUpdates returns a chan filled whenever a new update is gotten.
+// pollBackoff sets how long GetUpdatesChan waits after failed polls.
+var pollBackoff = &RetryPolicy{
+	BaseDelay: time.Second,
+	MaxDelay:  time.Minute,
+	Jitter:    0.2,
+}
+
+// GetUpdatesChan returns a chan filled whenever a new update is gotten.
+// Call StopReceivingUpdates to stop polling and close the chan.
+//
+// Failed polls are passed to config.ErrorHandler and retried with an
+// increasing delay. Errors that can't be recovered from by retrying,
+// like a revoked token or another instance receiving updates, stop
+// polling and close the chan; UpdatesError returns the reason.
 func (bot *Bot) GetUpdatesChan(config UpdateConfig) (chan Update, error) {
+	return bot.GetUpdatesChanContext(context.Background(), config)
+}
 
-	offset := config.Offset
-	limit := config.Limit
-	timeout := config.Timeout
+// GetUpdatesChanContext is like GetUpdatesChan, but polling also stops
+// and the chan is closed when ctx is done.
+func (bot *Bot) GetUpdatesChanContext(ctx context.Context, config UpdateConfig) (chan Update, error) {
+	bot.updatesMu.Lock()
+	defer bot.updatesMu.Unlock()
 
-	bot.updates = make(chan Update, 100)
+	if bot.stopUpdates != nil {
+		return nil, errors.New("already receiving updates")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	updates := make(chan Update, 100)
+	done := make(chan struct{})
+
+	bot.stopUpdates = cancel
+	bot.updatesDone = done
+	bot.updatesOffset = config.Offset
+	bot.updatesErr = nil
 
 	go func() {
-		defer close(bot.updates)
+		defer func() {
+			cancel()
+			close(updates)
+
+			bot.updatesMu.Lock()
+			bot.stopUpdates = nil
+			bot.updatesDone = nil
+			bot.updatesMu.Unlock()
 
+			close(done)
+		}()
+
+		failures := 0
 		for {
-			v := url.Values{}
-			if offset > 0 {
-				v.Add("offset", strconv.Itoa(offset))
-			}
-			if limit > 0 {
-				v.Add("limit", strconv.Itoa(limit))
-			}
-			if timeout > 0 {
-				v.Add("timeout", strconv.Itoa(timeout))
+			list, err := bot.GetUpdatesContext(ctx, config)
+			if ctx.Err() != nil {
+				return
 			}
+			if err != nil {
+				failures++
 
-			resp, err := bot.MakeRequest("getUpdates", v)
-			if err == nil {
-				var updates []Update
-				json.Unmarshal(resp.Result, &updates)
-
+				if config.ErrorHandler != nil {
+					config.ErrorHandler(err)
+				}
 				if bot.Debug {
-					log.Printf("getUpdates: %+v\n", updates)
+					log.Printf("getUpdates failed: %v\n", err)
+				}
+
+				if isFatalPollError(err) {
+					bot.updatesMu.Lock()
+					bot.updatesErr = fmt.Errorf("stopped receiving updates: %w", err)
+					bot.updatesMu.Unlock()
+					return
+				}
+
+				if sleepContext(ctx, pollBackoff.delay(failures, err)) != nil {
+					return
 				}
+				continue
+			}
+			failures = 0
 
-				for _, e := range updates {
-					if e.UpdateID >= offset {
-						offset = e.UpdateID + 1
-					}
+			for _, e := range list {
+				select {
+				case updates <- e:
+				case <-ctx.Done():
+					return
+				}
 
-					bot.updates <- e
+				if e.UpdateID >= config.Offset {
+					config.Offset = e.UpdateID + 1
+					bot.setUpdatesOffset(config.Offset)
 				}
 			}
 		}
 	}()
 
-	return bot.updates, nil
+	return updates, nil
+}
+
+// StopReceivingUpdates stops the loop started by GetUpdatesChan, aborting
+// any request in progress, and waits for the chan to be closed.
+// Updates already in the chan can still be read after it is closed.
+//
+// It returns the offset to pass to the next GetUpdatesChan so polling
+// resumes after the last update put in the chan, without losing or
+// repeating any.
+func (bot *Bot) StopReceivingUpdates() int {
+	bot.updatesMu.Lock()
+	stop, done := bot.stopUpdates, bot.updatesDone
+	bot.updatesMu.Unlock()
+
+	if stop != nil {
+		stop()
+		<-done
+	}
+
+	bot.updatesMu.Lock()
+	defer bot.updatesMu.Unlock()
+
+	return bot.updatesOffset
+}
+
+// UpdatesError returns the error that stopped the last GetUpdatesChan,
+// or nil if it is still running or was stopped by the caller.
+func (bot *Bot) UpdatesError() error {
+	bot.updatesMu.Lock()
+	defer bot.updatesMu.Unlock()
+
+	return bot.updatesErr
+}
+
+// ListenForWebhook registers a handler for pattern on http.DefaultServeMux
+// and returns a chan filled with the updates Telegram posts to it,
+// just like the one returned by GetUpdatesChan.
+//
+// Start the server with http.ListenAndServe or http.ListenAndServeTLS.
+func (bot *Bot) ListenForWebhook(pattern string) chan Update {
+	updates := make(chan Update, 100)
+	http.Handle(pattern, bot.WebhookHandler(updates))
+
+	return updates
+}
+
+// webhookQueueWait is how long WebhookHandler waits for room in a full
+// chan before turning an update away.
+const webhookQueueWait = time.Second
+
+// WebhookHandler returns an http.Handler that decodes the updates
+// Telegram posts to a webhook and sends them to updates.
+//
+// If updates is full, the handler waits up to a second for room, then
+// answers 503 Service Unavailable without holding the connection open, and
+// Telegram delivers the update again later.
+//
+// If the webhook was set with a SecretToken, requests without a matching
+// X-Telegram-Bot-Api-Secret-Token header are rejected.
+func (bot *Bot) WebhookHandler(updates chan<- Update) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		bot.updatesMu.Lock()
+		secret := bot.webhookSecret
+		bot.updatesMu.Unlock()
+
+		token := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+		if secret != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			http.Error(w, "invalid secret token", http.StatusForbidden)
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			http.Error(w, "content type must be application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		var update Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&update); err != nil {
+			http.Error(w, "invalid update", http.StatusBadRequest)
+			return
+		}
+
+		if bot.Debug {
+			log.Printf("webhook: %+v\n", update)
+		}
+
+		timer := time.NewTimer(webhookQueueWait)
+		defer timer.Stop()
+
+		select {
+		case updates <- update:
+			w.WriteHeader(http.StatusOK)
+		case <-timer.C:
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		case <-r.Context().Done():
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		}
+	})
+}
+
+// isFatalPollError reports whether polling can't succeed by retrying.
+// Telegram answers 401 or 404 for invalid tokens, and 409 when a webhook
+// is set or another getUpdates request is running.
+func isFatalPollError(err error) bool {
+	return IsUnauthorized(err) || IsConflict(err) || hasCode(err, http.StatusNotFound)
+}
 
+// setUpdatesOffset records the offset confirmed by the polling loop.
+func (bot *Bot) setUpdatesOffset(offset int) {
+	bot.updatesMu.Lock()
+	bot.updatesOffset = offset
+	bot.updatesMu.Unlock()
 }
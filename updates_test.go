@@ -0,0 +1,196 @@
+package tgbotapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStopReceivingUpdates(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("offset") == "" {
+			w.Write([]byte(`{"ok":true,"result":[{"update_id":41,"message":{"text":"a"}},{"update_id":42,"message":{"text":"b"}}]}`))
+			return
+		}
+
+		// Long poll with nothing new.
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	})
+
+	updates, err := bot.GetUpdatesChan(UpdateConfig{Timeout: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, text := range []string{"a", "b"} {
+		if e := <-updates; e.Message.Text != text {
+			t.Errorf("expected %q, got %q", text, e.Message.Text)
+		}
+	}
+
+	if _, err := bot.GetUpdatesChan(UpdateConfig{}); err == nil {
+		t.Error("expected an error while already receiving updates")
+	}
+
+	if offset := bot.StopReceivingUpdates(); offset != 43 {
+		t.Errorf("expected offset 43, got %d", offset)
+	}
+
+	if _, ok := <-updates; ok {
+		t.Error("expected the chan to be closed")
+	}
+}
+
+func TestGetUpdatesChanFatalError(t *testing.T) {
+	defer func(p *RetryPolicy) { pollBackoff = p }(pollBackoff)
+	pollBackoff = &RetryPolicy{BaseDelay: time.Millisecond}
+
+	calls := 0
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.WriteHeader(http.StatusInternalServerError)
+		case 2:
+			w.Write([]byte(`{"ok":true,"result":"not a list"}`))
+		default:
+			w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+		}
+	})
+
+	var errs []error
+	updates, err := bot.GetUpdatesChan(UpdateConfig{
+		ErrorHandler: func(err error) { errs = append(errs, err) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := <-updates; ok {
+		t.Fatal("expected the chan to be closed")
+	}
+
+	if len(errs) != 3 {
+		t.Errorf("expected 3 errors, got %v", errs)
+	}
+	if err := bot.UpdatesError(); !IsUnauthorized(err) {
+		t.Errorf("expected polling to stop with 401, got %v", err)
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	bot := &Bot{}
+	updates := make(chan Update, 1)
+	handler := bot.WebhookHandler(updates)
+
+	tests := []struct {
+		method      string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"GET", "application/json", "", http.StatusMethodNotAllowed},
+		{"POST", "text/plain", `{"update_id":1}`, http.StatusUnsupportedMediaType},
+		{"POST", "application/json", `{"update_id":`, http.StatusBadRequest},
+		{"POST", "application/json; charset=utf-8", `{"update_id":7,"message":{"text":"hi"}}`, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, "/hook", strings.NewReader(test.body))
+		r.Header.Set("Content-Type", test.contentType)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s %s: expected status %d, got %d", test.method, test.contentType, test.status, w.Code)
+		}
+	}
+
+	select {
+	case e := <-updates:
+		if e.UpdateID != 7 || e.Message.Text != "hi" {
+			t.Errorf("unexpected update %+v", e)
+		}
+	default:
+		t.Error("expected an update")
+	}
+}
+
+func TestWebhookHandlerFull(t *testing.T) {
+	bot := &Bot{}
+	updates := make(chan Update, 1)
+	updates <- Update{UpdateID: 1}
+	handler := bot.WebhookHandler(updates)
+
+	r := httptest.NewRequest("POST", "/hook", strings.NewReader(`{"update_id":2}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	start := time.Now()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+	if waited := time.Since(start); waited > 2*webhookQueueWait {
+		t.Errorf("expected the update to be turned away quickly, waited %v", waited)
+	}
+	if len(updates) != 1 {
+		t.Errorf("expected only the first update, got %d", len(updates))
+	}
+}
+
+func TestSetWebhookSecretToken(t *testing.T) {
+	bot, _ := newTestBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: bad form"}`))
+			return
+		}
+		if _, _, err := r.FormFile("certificate"); err != nil {
+			t.Error("expected a certificate upload")
+		}
+		if r.FormValue("url") != "https://example.com/hook" || r.FormValue("secret_token") != "s3cret" {
+			t.Errorf("unexpected form %v", r.MultipartForm.Value)
+		}
+		if r.FormValue("allowed_updates") != `["message"]` {
+			t.Errorf("unexpected allowed_updates %q", r.FormValue("allowed_updates"))
+		}
+
+		w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+
+	config, err := NewWebhookWithCert("https://example.com/hook", "README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.AllowedUpdates = []string{"message"}
+	config.SecretToken = "s3cret"
+
+	if err := bot.SetWebhook(config); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := bot.WebhookHandler(make(chan Update, 1))
+	for token, status := range map[string]int{"": http.StatusForbidden, "wrong": http.StatusForbidden, "s3cret": http.StatusOK} {
+		r := httptest.NewRequest("POST", "/hook", strings.NewReader(`{"update_id":1}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("X-Telegram-Bot-Api-Secret-Token", token)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, r)
+		if w.Code != status {
+			t.Errorf("token %q: expected status %d, got %d", token, status, w.Code)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package tgbotapi
+
+import "sync"
+
+// maxQueuedPerWorker limits how many updates ServeConcurrent reads ahead
+// of the workers. Once reached, it stops reading until one is handled, so
+// the updates chan fills up and polling waits instead of piling up updates
+// in memory.
+const maxQueuedPerWorker = 8
+
+// ServeConcurrent calls handle with updates until the chan is closed,
+// handling updates from up to workers chats at the same time. Updates from
+// the same chat are always handled one at a time, in the order they came.
+//
+// It returns once every update read has been handled.
+func ServeConcurrent(updates <-chan Update, workers int, handle func(update Update)) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		mu      sync.Mutex
+		queues  = make(map[int][]Update)
+		running = make(chan struct{}, workers)
+		queued  = make(chan struct{}, workers*maxQueuedPerWorker)
+		wg      sync.WaitGroup
+	)
+
+	// work handles the updates queued for a chat until there are none left.
+	work := func(chatID int) {
+		defer func() {
+			<-running
+			wg.Done()
+		}()
+
+		for {
+			mu.Lock()
+			queue := queues[chatID]
+			if len(queue) == 0 {
+				delete(queues, chatID)
+				mu.Unlock()
+				return
+			}
+			update := queue[0]
+			queues[chatID] = queue[1:]
+			mu.Unlock()
+
+			handle(update)
+			<-queued
+		}
+	}
+
+	for update := range updates {
+		queued <- struct{}{}
+
+		chatID := updateChatID(update)
+
+		mu.Lock()
+		queue, busy := queues[chatID]
+		queues[chatID] = append(queue, update)
+		mu.Unlock()
+
+		if !busy {
+			running <- struct{}{}
+			wg.Add(1)
+			go work(chatID)
+		}
+	}
+
+	wg.Wait()
+}
+
+// ServeConcurrent is like Serve, but handles updates from up to workers
+// chats at the same time while keeping the updates from each chat in order.
+func (r *Router) ServeConcurrent(updates <-chan Update, workers int) {
+	ServeConcurrent(updates, workers, r.HandleUpdate)
+}
+
+// updateChatID returns the chat an update belongs to.
+func updateChatID(update Update) int {
+	if q := update.CallbackQuery; q != nil && q.Message != nil {
+		return q.Message.Chat.ID
+	}
+
+	return update.Message.Chat.ID
+}
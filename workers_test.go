@@ -0,0 +1,84 @@
+package tgbotapi
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestServeConcurrent(t *testing.T) {
+	updates := make(chan Update, 100)
+	for i := 1; i <= 30; i++ {
+		updates <- Update{UpdateID: i, Message: Message{Chat: UserOrGroupChat{ID: i % 3}}}
+	}
+	close(updates)
+
+	var (
+		mu      sync.Mutex
+		last    = make(map[int]int)
+		running int
+		most    int
+	)
+
+	ServeConcurrent(updates, 2, func(update Update) {
+		mu.Lock()
+		running++
+		if running > most {
+			most = running
+		}
+		chatID := update.Message.Chat.ID
+		if update.UpdateID < last[chatID] {
+			t.Errorf("chat %d: update %d handled after %d", chatID, update.UpdateID, last[chatID])
+		}
+		last[chatID] = update.UpdateID
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+
+	if most != 2 {
+		t.Errorf("expected 2 updates handled at a time, got %d", most)
+	}
+	for chatID, id := range map[int]int{0: 30, 1: 28, 2: 29} {
+		if last[chatID] != id {
+			t.Errorf("chat %d: expected last update %d, got %d", chatID, id, last[chatID])
+		}
+	}
+}
+
+func TestServeConcurrentBackpressure(t *testing.T) {
+	updates := make(chan Update, 100)
+	release := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		ServeConcurrent(updates, 1, func(update Update) {
+			<-release
+		})
+		close(done)
+	}()
+
+	for i := 1; i <= 100; i++ {
+		updates <- Update{UpdateID: i, Message: Message{Chat: UserOrGroupChat{ID: 1}}}
+	}
+
+	// The handler is stuck, so only the queued updates and the one
+	// waiting for room in the queue should have been read.
+	time.Sleep(20 * time.Millisecond)
+	if read := 100 - len(updates); read > maxQueuedPerWorker+1 {
+		t.Errorf("expected at most %d updates to be read, got %d", maxQueuedPerWorker+1, read)
+	}
+
+	close(release)
+	close(updates)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected ServeConcurrent to return once the updates were handled")
+	}
+}